@@ -0,0 +1,248 @@
+package zerolog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AsyncPolicy defines how an AsyncWriter handles writes when its queue is
+// full.
+type AsyncPolicy int
+
+const (
+	// AsyncBlock blocks the writes until there is room in the queue.
+	AsyncBlock AsyncPolicy = iota
+	// AsyncDropNewest drops the written event.
+	AsyncDropNewest
+	// AsyncDropOldest drops the oldest queued event to make room for the
+	// written event.
+	AsyncDropOldest
+	// AsyncDropBelowLevel drops the written event if its level is below the
+	// AsyncWriter Level and blocks otherwise, so important events are never
+	// lost.
+	AsyncDropBelowLevel
+)
+
+// ErrAsyncWriterClosed is returned by writes on a closed AsyncWriter.
+var ErrAsyncWriterClosed = errors.New("zerolog: write on closed AsyncWriter")
+
+var asyncEntryPool = &sync.Pool{
+	New: func() interface{} {
+		return &asyncEntry{p: make([]byte, 0, 500)}
+	},
+}
+
+type asyncEntry struct {
+	p       []byte
+	level   Level
+	leveled bool // written using WriteLevel
+}
+
+// AsyncWriter is a LevelWriter writing events to Out from background worker
+// goroutines through a bounded queue, so log producers are not slowed down by
+// a slow writer. What happens when the queue is full is defined by Policy.
+//
+// Unlike diode.Writer, it can be configured to never lose events, or only
+// lose events below a given level. Errors returned by Out are reported to
+// ErrorHandler.
+//
+// Use NewAsyncWriter to create an AsyncWriter. Close or Flush it before the
+// program exits to write pending events.
+type AsyncWriter struct {
+	// Out is the destination writer. If it implements LevelWriter, its
+	// WriteLevel method is used for events written with WriteLevel.
+	Out io.Writer
+
+	// QueueSize is the maximum number of pending events. Default is 1000.
+	QueueSize int
+
+	// Policy defines what to do when the queue is full. Default is AsyncBlock.
+	Policy AsyncPolicy
+
+	// Level is the minimum level of the events which are never dropped when
+	// using the AsyncDropBelowLevel policy.
+	Level Level
+
+	// Workers is the number of goroutines writing to Out. Default is 1. Note
+	// that events may be written out of order with more than one worker, and
+	// that Out must then be safe for concurrent use.
+	Workers int
+
+	ch      chan *asyncEntry
+	mu      sync.RWMutex // protects closed and ch from being closed during a write
+	closed  bool
+	wg      sync.WaitGroup
+	pending atomic.Int64
+	dropped atomic.Uint64
+}
+
+// NewAsyncWriter creates an AsyncWriter writing to out and starts its workers.
+func NewAsyncWriter(out io.Writer, options ...func(w *AsyncWriter)) *AsyncWriter {
+	w := &AsyncWriter{
+		Out:       out,
+		QueueSize: 1000,
+		Workers:   1,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	if w.QueueSize < 1 {
+		w.QueueSize = 1
+	}
+	if w.Workers < 1 {
+		w.Workers = 1
+	}
+	w.ch = make(chan *asyncEntry, w.QueueSize)
+	lw, _ := w.Out.(LevelWriter)
+	w.wg.Add(w.Workers)
+	for i := 0; i < w.Workers; i++ {
+		go w.work(lw)
+	}
+	return w
+}
+
+// Write implements the io.Writer interface.
+func (w *AsyncWriter) Write(p []byte) (n int, err error) {
+	return w.write(NoLevel, false, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *AsyncWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(l, true, p)
+}
+
+func (w *AsyncWriter) write(l Level, leveled bool, p []byte) (n int, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, ErrAsyncWriterClosed
+	}
+
+	e := asyncEntryPool.Get().(*asyncEntry)
+	// p is pooled in zerolog so we can't hold it passed this call, hence the
+	// copy.
+	e.p = append(e.p[:0], p...)
+	e.level = l
+	e.leveled = leveled
+	w.pending.Add(1)
+
+	select {
+	case w.ch <- e:
+		return len(p), nil
+	default:
+	}
+
+	switch w.Policy {
+	case AsyncDropNewest:
+		w.drop(e)
+		return len(p), nil
+	case AsyncDropOldest:
+		for {
+			select {
+			case old := <-w.ch:
+				w.drop(old)
+			default:
+			}
+			select {
+			case w.ch <- e:
+				return len(p), nil
+			default:
+			}
+		}
+	case AsyncDropBelowLevel:
+		if l < w.Level {
+			w.drop(e)
+			return len(p), nil
+		}
+	}
+	w.ch <- e
+	return len(p), nil
+}
+
+func (w *AsyncWriter) drop(e *asyncEntry) {
+	w.dropped.Add(1)
+	w.pending.Add(-1)
+	putAsyncEntry(e)
+}
+
+func (w *AsyncWriter) work(lw LevelWriter) {
+	defer w.wg.Done()
+	for e := range w.ch {
+		var err error
+		if e.leveled && lw != nil {
+			_, err = lw.WriteLevel(e.level, e.p)
+		} else {
+			_, err = w.Out.Write(e.p)
+		}
+		if err != nil {
+			if ErrorHandler != nil {
+				ErrorHandler(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v\n", err)
+			}
+		}
+		putAsyncEntry(e)
+		w.pending.Add(-1)
+	}
+}
+
+func putAsyncEntry(e *asyncEntry) {
+	// Proper usage of a sync.Pool requires each entry to have approximately
+	// the same memory cost. To obtain this property when the stored type
+	// contains a variably-sized buffer, we add a hard limit on the maximum buffer
+	// to place back in the pool.
+	//
+	// See https://golang.org/issue/23199
+	const maxSize = 1 << 16 // 64KiB
+	if cap(e.p) <= maxSize {
+		asyncEntryPool.Put(e)
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush waits until all pending events are written to Out or ctx is done, in
+// which case ctx.Err() is returned.
+func (w *AsyncWriter) Flush(ctx context.Context) error {
+	if w.pending.Load() == 0 {
+		return nil
+	}
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for w.pending.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Close writes pending events, stops the workers and calls Close on Out if it
+// implements io.Closer. Subsequent writes return ErrAsyncWriterClosed.
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.ch)
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	if closer, ok := w.Out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package zerolog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks writes until its gate is closed and records them.
+type gatedWriter struct {
+	gate   chan struct{}
+	mu     sync.Mutex
+	writes []string
+	levels []Level
+	closed bool
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *gatedWriter) WriteLevel(l Level, p []byte) (int, error) {
+	w.mu.Lock()
+	w.levels = append(w.levels, l)
+	w.mu.Unlock()
+	return w.Write(p)
+}
+
+func (w *gatedWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestAsyncWriter(t *testing.T) {
+	gw := newGatedWriter()
+	close(gw.gate)
+	w := NewAsyncWriter(gw)
+	w.WriteLevel(InfoLevel, []byte("a\n"))
+	w.Write([]byte("b\n"))
+	w.WriteLevel(ErrorLevel, []byte("c\n"))
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a\n", "b\n", "c\n"}; !reflect.DeepEqual(gw.writes, want) {
+		t.Errorf("Invalid writes, got: %q, want: %q", gw.writes, want)
+	}
+	if want := []Level{InfoLevel, ErrorLevel}; !reflect.DeepEqual(gw.levels, want) {
+		t.Errorf("Invalid levels, got: %v, want: %v", gw.levels, want)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !gw.closed {
+		t.Error("Close was not called on the underlying writer")
+	}
+	if _, err := w.Write([]byte("d\n")); err != ErrAsyncWriterClosed {
+		t.Errorf("Expected ErrAsyncWriterClosed, got: %v", err)
+	}
+}
+
+// fill writes n events once the worker is blocked on the first one.
+func fill(t *testing.T, w *AsyncWriter, n int) {
+	t.Helper()
+	w.WriteLevel(InfoLevel, []byte("0\n"))
+	deadline := time.Now().Add(time.Second)
+	for len(w.ch) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Worker did not pick the first event")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 1; i < n; i++ {
+		w.WriteLevel(InfoLevel, []byte{byte('0' + i), '\n'})
+	}
+}
+
+func TestAsyncWriterPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  AsyncPolicy
+		level   Level
+		want    []string
+		dropped uint64
+	}{
+		{"DropNewest", AsyncDropNewest, NoLevel, []string{"0\n", "1\n", "2\n"}, 2},
+		{"DropOldest", AsyncDropOldest, NoLevel, []string{"0\n", "w\n", "e\n"}, 2},
+		{"DropBelowLevel", AsyncDropBelowLevel, ErrorLevel, []string{"0\n", "1\n", "2\n", "e\n"}, 1},
+		{"Block", AsyncBlock, NoLevel, []string{"0\n", "1\n", "2\n", "w\n", "e\n"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := newGatedWriter()
+			w := NewAsyncWriter(gw, func(w *AsyncWriter) {
+				w.QueueSize = 2
+				w.Policy = tt.policy
+				w.Level = tt.level
+			})
+			fill(t, w, 3)
+			done := make(chan struct{})
+			go func() {
+				w.WriteLevel(WarnLevel, []byte("w\n"))
+				w.WriteLevel(ErrorLevel, []byte("e\n"))
+				close(done)
+			}()
+			if tt.policy == AsyncBlock || tt.policy == AsyncDropBelowLevel {
+				select {
+				case <-done:
+					t.Fatal("Write did not block")
+				case <-time.After(20 * time.Millisecond):
+				}
+				close(gw.gate)
+				<-done
+			} else {
+				<-done
+				close(gw.gate)
+			}
+			w.Close()
+			if !reflect.DeepEqual(gw.writes, tt.want) {
+				t.Errorf("Invalid writes, got: %q, want: %q", gw.writes, tt.want)
+			}
+			if got := w.Dropped(); got != tt.dropped {
+				t.Errorf("Invalid dropped count, got: %d, want: %d", got, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestAsyncWriterFlushTimeout(t *testing.T) {
+	gw := newGatedWriter()
+	w := NewAsyncWriter(gw)
+	w.Write([]byte("a\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded error, got: %v", err)
+	}
+	close(gw.gate)
+	w.Close()
+}
+
+type countingWriter struct {
+	n atomic.Int32
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n.Add(1)
+	return len(p), nil
+}
+
+func TestAsyncWriterWorkers(t *testing.T) {
+	cw := &countingWriter{}
+	w := NewAsyncWriter(cw, func(w *AsyncWriter) {
+		w.Workers = 4
+	})
+	log := New(w)
+	for i := 0; i < 100; i++ {
+		log.Info().Int("i", i).Msg("")
+	}
+	w.Close()
+	if n := cw.n.Load(); n != 100 {
+		t.Errorf("Expected 100 events, got: %d", n)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("boom")
+}
+
+func TestAsyncWriterError(t *testing.T) {
+	var got error
+	ErrorHandler = func(err error) { got = err }
+	defer func() { ErrorHandler = nil }()
+	w := NewAsyncWriter(failingWriter{})
+	w.Write([]byte("a\n"))
+	w.Close()
+	if got == nil || got.Error() != "boom" {
+		t.Errorf("Expected boom error, got: %v", got)
+	}
+}
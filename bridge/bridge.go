@@ -0,0 +1,279 @@
+// Package bridge redirects the output of other logging APIs to zerolog: the
+// standard log package, klog-style text output and the grpclog.LoggerV2
+// interface.
+//
+// Lines written by other loggers are converted into events with a level
+// detected from their prefix (like "[WARN] " or "error: "), the caller
+// extracted from the file:line header of the log and klog packages, and the
+// message trimmed of these headers and of trailing new lines.
+package bridge
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	// klogHeader matches the header of klog and glog lines:
+	// Lmmdd hh:mm:ss.uuuuuu threadid file:line] msg
+	klogHeader = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}\.\d{6}\s+\d+ ([^ \]]+:\d+)\] `)
+
+	// stdHeader matches the header added by the standard log package flags.
+	stdHeader = regexp.MustCompile(`^(?:\d{4}/\d{2}/\d{2} )?(?:\d{2}:\d{2}:\d{2}(?:\.\d+)? )?(?:(\S+:\d+): )?`)
+
+	// levelPrefix matches a level at the beginning of a message, like
+	// "[WARN] " or "error: ".
+	levelPrefix = regexp.MustCompile(`^(?i)(?:\[(trace|debug|info|warn|warning|error|err|fatal|panic)\]|(trace|debug|info|warn|warning|error|err|fatal|panic):)\s*`)
+)
+
+// Writer is an io.Writer converting each write of another logger into a
+// zerolog event.
+type Writer struct {
+	// Logger is the logger the events are sent to.
+	Logger zerolog.Logger
+
+	// Level is the level of the events without a detected level. Default is
+	// zerolog.InfoLevel.
+	Level zerolog.Level
+}
+
+// NewWriter creates a Writer sending events to l.
+func NewWriter(l zerolog.Logger, options ...func(w *Writer)) *Writer {
+	w := &Writer{
+		Logger: l,
+		Level:  zerolog.InfoLevel,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements the io.Writer interface. Each write is logged as a single
+// event. Fatal and panic levels are logged without exiting or panicking.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	level, caller, msg := w.parse(string(p))
+	e := w.Logger.WithLevel(level)
+	if e == nil {
+		return len(p), nil
+	}
+	if caller != "" {
+		e.Str(zerolog.CallerFieldName, caller)
+	}
+	e.Msg(msg)
+	return len(p), nil
+}
+
+// parse extracts the level and caller of a line and trims the message.
+func (w *Writer) parse(line string) (level zerolog.Level, caller, msg string) {
+	level = w.Level
+	msg = strings.TrimRight(line, "\r\n")
+	if m := klogHeader.FindStringSubmatch(msg); m != nil {
+		switch m[1] {
+		case "I":
+			level = zerolog.InfoLevel
+		case "W":
+			level = zerolog.WarnLevel
+		case "E":
+			level = zerolog.ErrorLevel
+		case "F":
+			level = zerolog.FatalLevel
+		}
+		caller = m[2]
+		msg = msg[len(m[0]):]
+	} else if m := stdHeader.FindStringSubmatch(msg); m != nil {
+		caller = m[1]
+		msg = msg[len(m[0]):]
+	}
+	if m := levelPrefix.FindStringSubmatch(msg); m != nil {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		level = parseLevel(name)
+		msg = msg[len(m[0]):]
+	}
+	return level, caller, strings.TrimSpace(msg)
+}
+
+// parseLevel parses a level name matched by levelPrefix.
+func parseLevel(name string) zerolog.Level {
+	switch strings.ToLower(name) {
+	case "trace":
+		return zerolog.TraceLevel
+	case "debug":
+		return zerolog.DebugLevel
+	case "warn", "warning":
+		return zerolog.WarnLevel
+	case "error", "err":
+		return zerolog.ErrorLevel
+	case "fatal":
+		return zerolog.FatalLevel
+	case "panic":
+		return zerolog.PanicLevel
+	}
+	return zerolog.InfoLevel
+}
+
+// NewStdLogger creates a standard library *log.Logger writing to l, for APIs
+// requiring one, like http.Server.ErrorLog.
+func NewStdLogger(l zerolog.Logger, options ...func(w *Writer)) *log.Logger {
+	return log.New(NewWriter(l, options...), "", log.Llongfile)
+}
+
+// RedirectStdLog redirects the output of the standard log package to l. The
+// log flags are set to log.Llongfile|log.Lmsgprefix so the caller of each
+// line is extracted and the log prefix is part of the message. It returns a
+// function restoring the previous output and flags.
+func RedirectStdLog(l zerolog.Logger, options ...func(w *Writer)) func() {
+	flags, out := log.Flags(), log.Writer()
+	log.SetFlags(log.Llongfile | log.Lmsgprefix)
+	log.SetOutput(NewWriter(l, options...))
+	return func() {
+		log.SetFlags(flags)
+		log.SetOutput(out)
+	}
+}
+
+// Logger implements the grpclog.LoggerV2 and grpclog.DepthLoggerV2
+// interfaces, which are also the Info, Warning, Error and Fatal API of klog,
+// with a zerolog logger:
+//
+//	grpclog.SetLoggerV2(bridge.NewLogger(log))
+//
+// Events include the caller of the logging method. The package does not depend
+// on grpc.
+type Logger struct {
+	// Logger is the logger the events are sent to.
+	Logger zerolog.Logger
+
+	// Verbosity is the verbosity level reported by V. Default is 0.
+	Verbosity int
+}
+
+// NewLogger creates a Logger sending events to l.
+func NewLogger(l zerolog.Logger, options ...func(l *Logger)) *Logger {
+	bl := &Logger{
+		Logger: l,
+	}
+	for _, opt := range options {
+		opt(bl)
+	}
+	return bl
+}
+
+// log sends msg at level, with the caller depth frames above the caller of
+// the Logger method.
+func (l *Logger) log(level zerolog.Level, depth int, msg string) {
+	var e *zerolog.Event
+	if level == zerolog.FatalLevel {
+		e = l.Logger.Fatal()
+	} else {
+		e = l.Logger.WithLevel(level)
+	}
+	e.Caller(depth + 2).Msg(strings.TrimRight(msg, "\n"))
+}
+
+// Info logs to info level, with arguments handled in the manner of fmt.Print.
+func (l *Logger) Info(args ...interface{}) {
+	l.log(zerolog.InfoLevel, 0, fmt.Sprint(args...))
+}
+
+// Infoln logs to info level, with arguments handled in the manner of
+// fmt.Println.
+func (l *Logger) Infoln(args ...interface{}) {
+	l.log(zerolog.InfoLevel, 0, fmt.Sprintln(args...))
+}
+
+// Infof logs to info level, with arguments handled in the manner of
+// fmt.Printf.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(zerolog.InfoLevel, 0, fmt.Sprintf(format, args...))
+}
+
+// InfoDepth logs to info level, with the caller depth frames above the caller
+// of InfoDepth.
+func (l *Logger) InfoDepth(depth int, args ...interface{}) {
+	l.log(zerolog.InfoLevel, depth, fmt.Sprint(args...))
+}
+
+// Warning logs to warn level, with arguments handled in the manner of
+// fmt.Print.
+func (l *Logger) Warning(args ...interface{}) {
+	l.log(zerolog.WarnLevel, 0, fmt.Sprint(args...))
+}
+
+// Warningln logs to warn level, with arguments handled in the manner of
+// fmt.Println.
+func (l *Logger) Warningln(args ...interface{}) {
+	l.log(zerolog.WarnLevel, 0, fmt.Sprintln(args...))
+}
+
+// Warningf logs to warn level, with arguments handled in the manner of
+// fmt.Printf.
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.log(zerolog.WarnLevel, 0, fmt.Sprintf(format, args...))
+}
+
+// WarningDepth logs to warn level, with the caller depth frames above the
+// caller of WarningDepth.
+func (l *Logger) WarningDepth(depth int, args ...interface{}) {
+	l.log(zerolog.WarnLevel, depth, fmt.Sprint(args...))
+}
+
+// Error logs to error level, with arguments handled in the manner of
+// fmt.Print.
+func (l *Logger) Error(args ...interface{}) {
+	l.log(zerolog.ErrorLevel, 0, fmt.Sprint(args...))
+}
+
+// Errorln logs to error level, with arguments handled in the manner of
+// fmt.Println.
+func (l *Logger) Errorln(args ...interface{}) {
+	l.log(zerolog.ErrorLevel, 0, fmt.Sprintln(args...))
+}
+
+// Errorf logs to error level, with arguments handled in the manner of
+// fmt.Printf.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(zerolog.ErrorLevel, 0, fmt.Sprintf(format, args...))
+}
+
+// ErrorDepth logs to error level, with the caller depth frames above the
+// caller of ErrorDepth.
+func (l *Logger) ErrorDepth(depth int, args ...interface{}) {
+	l.log(zerolog.ErrorLevel, depth, fmt.Sprint(args...))
+}
+
+// Fatal logs to fatal level, with arguments handled in the manner of
+// fmt.Print, and exits (see zerolog.Logger.Fatal).
+func (l *Logger) Fatal(args ...interface{}) {
+	l.log(zerolog.FatalLevel, 0, fmt.Sprint(args...))
+}
+
+// Fatalln logs to fatal level, with arguments handled in the manner of
+// fmt.Println, and exits (see zerolog.Logger.Fatal).
+func (l *Logger) Fatalln(args ...interface{}) {
+	l.log(zerolog.FatalLevel, 0, fmt.Sprintln(args...))
+}
+
+// Fatalf logs to fatal level, with arguments handled in the manner of
+// fmt.Printf, and exits (see zerolog.Logger.Fatal).
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.log(zerolog.FatalLevel, 0, fmt.Sprintf(format, args...))
+}
+
+// FatalDepth logs to fatal level, with the caller depth frames above the
+// caller of FatalDepth, and exits (see zerolog.Logger.Fatal).
+func (l *Logger) FatalDepth(depth int, args ...interface{}) {
+	l.log(zerolog.FatalLevel, depth, fmt.Sprint(args...))
+}
+
+// V reports whether verbosity level v is enabled.
+func (l *Logger) V(v int) bool {
+	return v <= l.Verbosity
+}
@@ -0,0 +1,165 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/internal/cbor"
+)
+
+// depthLoggerV2 mirrors grpclog.DepthLoggerV2 so that the package does not
+// depend on grpc. The zgrpc tests check the actual grpclog interface.
+type depthLoggerV2 interface {
+	Info(args ...interface{})
+	Infoln(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningln(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorln(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalln(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	V(l int) bool
+	InfoDepth(depth int, args ...interface{})
+	WarningDepth(depth int, args ...interface{})
+	ErrorDepth(depth int, args ...interface{})
+	FatalDepth(depth int, args ...interface{})
+}
+
+var _ depthLoggerV2 = (*Logger)(nil)
+
+func decodeEvents(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var events []map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(cbor.DecodeIfBinaryToString(out.Bytes())))
+	for d.More() {
+		var m map[string]interface{}
+		if err := d.Decode(&m); err != nil {
+			t.Fatalf("invalid output %q: %v", out.String(), err)
+		}
+		events = append(events, m)
+	}
+	return events
+}
+
+func TestWriterParse(t *testing.T) {
+	w := NewWriter(zerolog.Nop())
+	tests := []struct {
+		line   string
+		level  zerolog.Level
+		caller string
+		msg    string
+	}{
+		{"hello\n", zerolog.InfoLevel, "", "hello"},
+		{"2009/01/23 01:23:23 /a/b/c/d.go:23: [WARN] disk full\n", zerolog.WarnLevel, "/a/b/c/d.go:23", "disk full"},
+		{"01:23:23.123123 d.go:23: error: connection refused\n", zerolog.ErrorLevel, "d.go:23", "connection refused"},
+		{"DEBUG: x\r\n", zerolog.DebugLevel, "", "x"},
+		{"[panic] y", zerolog.PanicLevel, "", "y"},
+		{"E0102 15:04:05.123456   12345 server.go:123] failed  \n", zerolog.ErrorLevel, "server.go:123", "failed"},
+		{"I0102 15:04:05.123456 1 server.go:1] started", zerolog.InfoLevel, "server.go:1", "started"},
+		{"information: not a level", zerolog.InfoLevel, "", "information: not a level"},
+	}
+	for _, tt := range tests {
+		level, caller, msg := w.parse(tt.line)
+		if level != tt.level || caller != tt.caller || msg != tt.msg {
+			t.Errorf("parse(%q) = %v, %q, %q, want %v, %q, %q", tt.line, level, caller, msg, tt.level, tt.caller, tt.msg)
+		}
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	out := &bytes.Buffer{}
+	restore := RedirectStdLog(zerolog.New(out), func(w *Writer) {
+		w.Level = zerolog.DebugLevel
+	})
+	_, file, line, _ := runtime.Caller(0)
+	log.Print("hello")
+	log.Printf("[ERROR] failed: %d", 42)
+	restore()
+	log.SetOutput(&bytes.Buffer{})
+	log.Print("not redirected")
+	restore()
+
+	events := decodeEvents(t, out)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %v", events)
+	}
+	want := []map[string]interface{}{
+		{"level": "debug", "caller": file + ":" + strconv.Itoa(line+1), "message": "hello"},
+		{"level": "error", "caller": file + ":" + strconv.Itoa(line+2), "message": "failed: 42"},
+	}
+	for i := range want {
+		for k, v := range want[i] {
+			if events[i][k] != v {
+				t.Errorf("event %d: invalid %s: got %v, want %v", i, k, events[i][k], v)
+			}
+		}
+	}
+}
+
+func TestNewStdLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	NewStdLogger(zerolog.New(out)).Println("warning: slow")
+	events := decodeEvents(t, out)
+	if len(events) != 1 || events[0]["level"] != "warn" || events[0]["message"] != "slow" {
+		t.Errorf("invalid events: %v", events)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := NewLogger(zerolog.New(out), func(l *Logger) {
+		l.Verbosity = 2
+	})
+	_, file, line, _ := runtime.Caller(0)
+	l.Infoln("a", "b")
+	l.Warningf("%d", 1)
+	l.ErrorDepth(0, "c")
+	func() {
+		l.InfoDepth(1, "d")
+	}()
+
+	events := decodeEvents(t, out)
+	want := []map[string]interface{}{
+		{"level": "info", "caller": file + ":" + strconv.Itoa(line+1), "message": "a b"},
+		{"level": "warn", "caller": file + ":" + strconv.Itoa(line+2), "message": "1"},
+		{"level": "error", "caller": file + ":" + strconv.Itoa(line+3), "message": "c"},
+		{"level": "info", "caller": file + ":" + strconv.Itoa(line+6), "message": "d"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %v", len(want), events)
+	}
+	for i := range want {
+		for k, v := range want[i] {
+			if events[i][k] != v {
+				t.Errorf("event %d: invalid %s: got %v, want %v", i, k, events[i][k], v)
+			}
+		}
+	}
+
+	if !l.V(2) || l.V(3) {
+		t.Errorf("invalid verbosity")
+	}
+}
+
+func TestLoggerFatal(t *testing.T) {
+	exited := false
+	zerolog.FatalExitFunc = func() { exited = true }
+	defer func() { zerolog.FatalExitFunc = nil }()
+
+	out := &bytes.Buffer{}
+	NewLogger(zerolog.New(out)).Fatalf("bye %s", "now")
+	events := decodeEvents(t, out)
+	if !exited || len(events) != 1 || events[0]["level"] != "fatal" || events[0]["message"] != "bye now" {
+		t.Errorf("invalid fatal: exited=%v, events=%v", exited, events)
+	}
+}
@@ -0,0 +1,187 @@
+package zerolog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the events written by a logger created from a Config.
+const (
+	// FormatJSON writes JSON events. It requires a build without the
+	// binary_log tag.
+	FormatJSON = "json"
+	// FormatCBOR writes CBOR events. It requires a build with the binary_log
+	// tag.
+	FormatCBOR = "cbor"
+	// FormatConsole writes human-friendly events with a ConsoleWriter.
+	FormatConsole = "console"
+)
+
+// Config defines a Logger, and the global settings it needs. Use
+// ConfigFromEnv or FromEnv to read it from environment variables.
+type Config struct {
+	// Level is the minimum level of the logger. Default, if nil, is
+	// TraceLevel.
+	Level *Level
+
+	// NamedLevels are the levels of the named loggers, in the format of
+	// ParseNamedLevels. They are left unchanged if empty.
+	NamedLevels string
+
+	// Format is FormatJSON, FormatCBOR or FormatConsole. Default is the
+	// encoding of the build: FormatCBOR with the binary_log tag, FormatJSON
+	// otherwise.
+	Format string
+
+	// Caller adds the caller to each event.
+	Caller bool
+
+	// Timestamp adds the time to each event.
+	Timestamp bool
+
+	// TimeFormat sets TimeFieldFormat. It is a time.Parse layout, or one of
+	// unix, unixms, unixmicro, unixnano, rfc3339 and rfc3339nano. It is left
+	// unchanged if empty.
+	TimeFormat string
+
+	// Sampling, if greater than 1, logs one event every Sampling events (see
+	// BasicSampler).
+	Sampling uint32
+
+	// Output is the writer of the logger. Default is os.Stderr.
+	Output io.Writer
+}
+
+// ConfigFromEnv reads a Config from the following environment variables:
+//
+//   - ZEROLOG_LEVEL: the level of the logger, trace by default.
+//   - ZEROLOG_LEVELS: the levels of the named loggers, like "db=debug,http=warn".
+//   - ZEROLOG_FORMAT: json, cbor or console.
+//   - ZEROLOG_CALLER: true to add the caller to each event, false by default.
+//   - ZEROLOG_TIMESTAMP: false to omit the time of the events, true by default.
+//   - ZEROLOG_TIME_FORMAT: the format of the time, see Config.TimeFormat.
+//   - ZEROLOG_SAMPLING: log one event every N events.
+func ConfigFromEnv() (Config, error) {
+	c := Config{
+		NamedLevels: os.Getenv("ZEROLOG_LEVELS"),
+		Format:      strings.ToLower(os.Getenv("ZEROLOG_FORMAT")),
+		Timestamp:   true,
+		TimeFormat:  os.Getenv("ZEROLOG_TIME_FORMAT"),
+	}
+	var err error
+	if s := os.Getenv("ZEROLOG_LEVEL"); s != "" {
+		l, err := ParseLevel(s)
+		if err != nil || l == NoLevel {
+			return c, fmt.Errorf("invalid ZEROLOG_LEVEL: %q", s)
+		}
+		c.Level = &l
+	}
+	if s := os.Getenv("ZEROLOG_CALLER"); s != "" {
+		if c.Caller, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid ZEROLOG_CALLER: %q", s)
+		}
+	}
+	if s := os.Getenv("ZEROLOG_TIMESTAMP"); s != "" {
+		if c.Timestamp, err = strconv.ParseBool(s); err != nil {
+			return c, fmt.Errorf("invalid ZEROLOG_TIMESTAMP: %q", s)
+		}
+	}
+	if s := os.Getenv("ZEROLOG_SAMPLING"); s != "" {
+		n, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return c, fmt.Errorf("invalid ZEROLOG_SAMPLING: %q", s)
+		}
+		c.Sampling = uint32(n)
+	}
+	return c, nil
+}
+
+// Logger creates the logger defined by c. It sets the global TimeFieldFormat
+// and named levels if c.TimeFormat and c.NamedLevels are not empty.
+func (c Config) Logger() (Logger, error) {
+	out := c.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	switch c.Format {
+	case "":
+	case FormatJSON:
+		if binaryEncoding {
+			return Logger{}, fmt.Errorf("format %s requires a build without the binary_log tag", c.Format)
+		}
+	case FormatCBOR:
+		if !binaryEncoding {
+			return Logger{}, fmt.Errorf("format %s requires a build with the binary_log tag", c.Format)
+		}
+	case FormatConsole:
+		out = NewConsoleWriter(func(w *ConsoleWriter) {
+			w.Out = out
+		})
+	default:
+		return Logger{}, fmt.Errorf("unknown format: %q", c.Format)
+	}
+
+	if c.NamedLevels != "" {
+		if err := ParseNamedLevels(c.NamedLevels); err != nil {
+			return Logger{}, err
+		}
+	}
+	if c.TimeFormat != "" {
+		TimeFieldFormat = timeFormat(c.TimeFormat)
+	}
+
+	level := TraceLevel
+	if c.Level != nil {
+		level = *c.Level
+	}
+	ctx := New(out).Level(level).With()
+	if c.Timestamp {
+		ctx = ctx.Timestamp()
+	}
+	if c.Caller {
+		ctx = ctx.Caller()
+	}
+	l := ctx.Logger()
+	if c.Sampling > 1 {
+		l = l.Sample(&BasicSampler{N: c.Sampling})
+	}
+	return l, nil
+}
+
+// FromEnv creates a logger from the environment variables documented in
+// ConfigFromEnv:
+//
+//	log, err := zerolog.FromEnv()
+//	if err != nil {
+//		panic(err)
+//	}
+func FromEnv() (Logger, error) {
+	c, err := ConfigFromEnv()
+	if err != nil {
+		return Logger{}, err
+	}
+	return c.Logger()
+}
+
+// timeFormat returns the TimeFieldFormat of a Config.TimeFormat.
+func timeFormat(s string) string {
+	switch strings.ToLower(s) {
+	case "unix":
+		return TimeFormatUnix
+	case "unixms":
+		return TimeFormatUnixMs
+	case "unixmicro":
+		return TimeFormatUnixMicro
+	case "unixnano":
+		return TimeFormatUnixNano
+	case "rfc3339":
+		return time.RFC3339
+	case "rfc3339nano":
+		return time.RFC3339Nano
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+package zerolog
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("ZEROLOG_LEVEL", "warn")
+	t.Setenv("ZEROLOG_LEVELS", "db=debug")
+	t.Setenv("ZEROLOG_FORMAT", "Console")
+	t.Setenv("ZEROLOG_CALLER", "true")
+	t.Setenv("ZEROLOG_TIMESTAMP", "false")
+	t.Setenv("ZEROLOG_TIME_FORMAT", "unixms")
+	t.Setenv("ZEROLOG_SAMPLING", "10")
+
+	c, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	warn := WarnLevel
+	want := Config{
+		Level:       &warn,
+		NamedLevels: "db=debug",
+		Format:      FormatConsole,
+		Caller:      true,
+		TimeFormat:  "unixms",
+		Sampling:    10,
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("invalid config:\ngot:  %+v\nwant: %+v", c, want)
+	}
+
+	t.Setenv("ZEROLOG_LEVEL", "")
+	if c, err := ConfigFromEnv(); err != nil || c.Level != nil {
+		t.Errorf("expected no level, got: %v, %v", c.Level, err)
+	}
+
+	for _, env := range []string{"ZEROLOG_LEVEL", "ZEROLOG_CALLER", "ZEROLOG_TIMESTAMP", "ZEROLOG_SAMPLING"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "invalid")
+			if _, err := ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), env) {
+				t.Errorf("expected an error about %s, got: %v", env, err)
+			}
+		})
+	}
+}
+
+func TestConfigLogger(t *testing.T) {
+	defer func(f string) { TimeFieldFormat = f }(TimeFieldFormat)
+	defer ParseNamedLevels("")
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+
+	out := &bytes.Buffer{}
+	info := InfoLevel
+	log, err := Config{
+		Level:       &info,
+		NamedLevels: "db=error",
+		Timestamp:   true,
+		TimeFormat:  "unix",
+		Output:      out,
+	}.Logger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Debug().Msg("filtered")
+	log.Info().Msg("logged")
+	want := `{"level":"info","time":981173106,"message":"logged"}` + "\n"
+	if binaryEncoding {
+		// CBOR encodes times natively.
+		want = `{"level":"info","time":"2001-02-03T04:05:06Z","message":"logged"}` + "\n"
+	}
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if l := log.Named("db"); l.GetLevel() != ErrorLevel {
+		t.Errorf("invalid named level: %v", l.GetLevel())
+	}
+
+	out.Reset()
+	log, err = Config{Format: FormatConsole, Caller: true, Output: out}.Logger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Trace().Msg("hello")
+	if got := out.String(); !strings.Contains(got, "TRC") || !strings.Contains(got, "config_test.go") || !strings.Contains(got, "hello") {
+		t.Errorf("invalid console output: %q", got)
+	}
+
+	log, err = Config{Sampling: 2, Output: out}.Logger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := log.sampler.(*BasicSampler); !ok || s.N != 2 {
+		t.Errorf("invalid sampler: %#v", log.sampler)
+	}
+
+	other := FormatCBOR
+	if binaryEncoding {
+		other = FormatJSON
+	}
+	for _, format := range []string{other, "xml"} {
+		if _, err := (Config{Format: format}).Logger(); err == nil {
+			t.Errorf("expected an error for format %s", format)
+		}
+	}
+}
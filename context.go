@@ -22,7 +22,7 @@ func (c Context) Logger() Logger {
 // Only map[string]interface{} and []interface{} are accepted. []interface{} must
 // alternate string keys and arbitrary values, and extraneous ones are ignored.
 func (c Context) Fields(fields interface{}) Context {
-	c.l.context = appendFields(c.l.context, fields, c.l.stack, c.l.ctx, c.l.hooks)
+	c.l.context = appendFields(c.l.context, fields, c.l.stack, c.l.ctx, c.l.hooks, c.l.schema)
 	return c
 }
 
@@ -208,21 +208,22 @@ func (c Context) Errs(key string, errs []error) Context {
 // Err adds the field "error" with serialized err to the logger context.
 func (c Context) Err(err error) Context {
 	if c.l.stack && ErrorStackMarshaler != nil {
+		stackFieldName := c.l.schema.errorStackFieldName()
 		switch m := ErrorStackMarshaler(err).(type) {
 		case nil:
 			return c // do nothing with nil errors
 		case LogObjectMarshaler:
-			c = c.Object(ErrorStackFieldName, m)
+			c = c.Object(stackFieldName, m)
 		case error:
-			c = c.Str(ErrorStackFieldName, m.Error())
+			c = c.Str(stackFieldName, m.Error())
 		case string:
-			c = c.Str(ErrorStackFieldName, m)
+			c = c.Str(stackFieldName, m)
 		default:
-			c = c.Interface(ErrorStackFieldName, m)
+			c = c.Interface(stackFieldName, m)
 		}
 	}
 
-	return c.AnErr(ErrorFieldName, err)
+	return c.AnErr(c.l.schema.errorFieldName(), err)
 }
 
 // Ctx adds the context.Context to the logger context. The context.Context is
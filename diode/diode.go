@@ -6,17 +6,29 @@ import (
 	"context"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/diode/internal/diodes"
 )
 
-var bufPool = &sync.Pool{
+var entryPool = &sync.Pool{
 	New: func() interface{} {
-		return make([]byte, 0, 500)
+		return &entry{p: make([]byte, 0, 500)}
 	},
 }
 
+// entry is the payload stored in the diode.
+type entry struct {
+	p       []byte
+	level   zerolog.Level
+	leveled bool // written using WriteLevel
+}
+
+// Alerter is called with the number of events overwritten in the diode
+// because the writer couldn't keep up. It is only called from the goroutine
+// reading the diode.
 type Alerter func(missed int)
 
 type diodeFetcher interface {
@@ -24,13 +36,125 @@ type diodeFetcher interface {
 	Next() diodes.GenericDataType
 }
 
+// Option configures a Writer.
+type Option func(o *options)
+
+type options struct {
+	blocking     bool
+	blockLevel   zerolog.Level
+	blockTimeout time.Duration
+	batchEvents  int
+	batchBytes   int
+	batchLatency time.Duration
+}
+
+// WithBlockingLevel makes writes of events at level or above wait up to
+// timeout for room in the diode instead of dropping older events. Once this
+// option is set, events below level are dropped when the diode is full
+// rather than overwriting queued events, so that events at level or above
+// are never lost unless the timeout is reached.
+//
+// Events dropped below level are counted in Stats but not reported to the
+// Alerter, which is only called from the goroutine reading the diode.
+//
+// The level is only known for events written using WriteLevel, which is the
+// case when the Writer is used as the output of a zerolog.Logger.
+func WithBlockingLevel(level zerolog.Level, timeout time.Duration) Option {
+	return func(o *options) {
+		o.blocking = true
+		o.blockLevel = level
+		o.blockTimeout = timeout
+	}
+}
+
+// WithBatching coalesces consecutive events into a single write on the
+// wrapped writer, which saves syscalls or network round trips for writers
+// like files or sockets. A batch is written once it holds maxEvents events or
+// maxBytes bytes (0 meaning no limit, at least one of them must be set), or
+// when no more events are queued and maxLatency has elapsed since the first
+// event of the batch was read. Events order is preserved.
+//
+// If the wrapped writer implements zerolog.LevelWriter, a batch only holds
+// events of the same level so it can be passed to WriteLevel.
+func WithBatching(maxEvents, maxBytes int, maxLatency time.Duration) Option {
+	return func(o *options) {
+		o.batchEvents = maxEvents
+		o.batchBytes = maxBytes
+		o.batchLatency = maxLatency
+	}
+}
+
+// Stats holds the counters of a Writer.
+type Stats struct {
+	// Written is the number of events written to the wrapped writer.
+	Written uint64
+	// Dropped is the number of events dropped because the diode was full.
+	Dropped uint64
+	// Queued is the number of events waiting in the diode.
+	Queued uint64
+}
+
+type counters struct {
+	enqueued atomic.Uint64
+	written  atomic.Uint64
+	// overwritten counts events dropped from the diode by the alerter,
+	// rejected events dropped before being added to the diode.
+	overwritten atomic.Uint64
+	rejected    atomic.Uint64
+
+	// space is closed, and replaced, each time events are written to the
+	// wrapped writer while writers are waiting for room in the diode.
+	mu      sync.Mutex
+	space   chan struct{}
+	waiting atomic.Int32
+}
+
+// wait returns a channel closed once events have been written.
+func (c *counters) wait() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.space == nil {
+		c.space = make(chan struct{})
+	}
+	return c.space
+}
+
+// signal wakes up the writers waiting for room in the diode.
+func (c *counters) signal() {
+	if c.waiting.Load() == 0 {
+		return
+	}
+	c.mu.Lock()
+	if c.space != nil {
+		close(c.space)
+		c.space = nil
+	}
+	c.mu.Unlock()
+}
+
+func (c *counters) queued() uint64 {
+	// Load in reverse order of increments so the result never underflows.
+	written := c.written.Load()
+	overwritten := c.overwritten.Load()
+	enqueued := c.enqueued.Load()
+	if n := written + overwritten; n < enqueued {
+		return enqueued - n
+	}
+	return 0
+}
+
 // Writer is a io.Writer wrapper that uses a diode to make Write lock-free,
 // non-blocking and thread safe.
 type Writer struct {
 	w    io.Writer
+	lw   zerolog.LevelWriter // w if it implements zerolog.LevelWriter
 	d    diodeFetcher
+	ctx  context.Context
 	c    context.CancelFunc
 	done chan struct{}
+	size uint64
+	opts options
+	cnt  *counters
 }
 
 // NewWriter creates a writer wrapping w with a many-to-one diode in order to
@@ -39,26 +163,39 @@ type Writer struct {
 //
 // Use a diode.Writer when
 //
-//     wr := diode.NewWriter(w, 1000, 0, func(missed int) {
-//         log.Printf("Dropped %d messages", missed)
-//     })
-//     log := zerolog.New(wr)
+//	wr := diode.NewWriter(w, 1000, 0, func(missed int) {
+//	    log.Printf("Dropped %d messages", missed)
+//	})
+//	log := zerolog.New(wr)
 //
 // If pollInterval is greater than 0, a poller is used otherwise a waiter is
 // used.
 //
+// If w implements zerolog.LevelWriter, events written using WriteLevel are
+// passed to its WriteLevel method with their level.
+//
 // See code.cloudfoundry.org/go-diodes for more info on diode.
-func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter) Writer {
+func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter, opts ...Option) Writer {
 	ctx, cancel := context.WithCancel(context.Background())
 	dw := Writer{
 		w:    w,
+		ctx:  ctx,
 		c:    cancel,
 		done: make(chan struct{}),
+		size: uint64(size),
+		cnt:  &counters{},
+	}
+	for _, opt := range opts {
+		opt(&dw.opts)
 	}
 	if f == nil {
 		f = func(int) {}
 	}
-	d := diodes.NewManyToOne(size, diodes.AlertFunc(f))
+	dw.lw, _ = w.(zerolog.LevelWriter)
+	d := diodes.NewManyToOne(size, diodes.AlertFunc(func(missed int) {
+		dw.cnt.overwritten.Add(uint64(missed))
+		f(missed)
+	}))
 	if pollInterval > 0 {
 		dw.d = diodes.NewPoller(d,
 			diodes.WithPollingInterval(pollInterval),
@@ -71,44 +208,235 @@ func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter) Wri
 	return dw
 }
 
+// Write implements the io.Writer interface.
 func (dw Writer) Write(p []byte) (n int, err error) {
+	dw.set(zerolog.NoLevel, false, p)
+	return len(p), nil
+}
+
+// WriteLevel implements the zerolog.LevelWriter interface.
+func (dw Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if dw.opts.blocking && !dw.waitSpace(level) {
+		dw.cnt.rejected.Add(1)
+		return len(p), nil
+	}
+	dw.set(level, true, p)
+	return len(p), nil
+}
+
+// waitSpace reports whether an event at level may be added to the diode,
+// waiting for the reader to make room if needed.
+func (dw Writer) waitSpace(level zerolog.Level) bool {
+	if dw.cnt.queued() < dw.size {
+		return true
+	}
+	if level < dw.opts.blockLevel {
+		return false
+	}
+	dw.cnt.waiting.Add(1)
+	defer dw.cnt.waiting.Add(-1)
+	timer := time.NewTimer(dw.opts.blockTimeout)
+	defer timer.Stop()
+	for {
+		space := dw.cnt.wait()
+		if dw.cnt.queued() < dw.size {
+			return true
+		}
+		select {
+		case <-space:
+		case <-timer.C:
+			// Let the event overwrite the oldest one rather than losing it.
+			return true
+		}
+	}
+}
+
+func (dw Writer) set(level zerolog.Level, leveled bool, p []byte) {
+	e := entryPool.Get().(*entry)
 	// p is pooled in zerolog so we can't hold it passed this call, hence the
 	// copy.
-	p = append(bufPool.Get().([]byte), p...)
-	dw.d.Set(diodes.GenericDataType(&p))
-	return len(p), nil
+	e.p = append(e.p[:0], p...)
+	e.level = level
+	e.leveled = leveled
+	dw.cnt.enqueued.Add(1)
+	dw.d.Set(diodes.GenericDataType(e))
 }
 
-// Close releases the diode poller and call Close on the wrapped writer if
-// io.Closer is implemented.
+// Stats returns the current counters of the writer.
+func (dw Writer) Stats() Stats {
+	return Stats{
+		Written: dw.cnt.written.Load(),
+		Dropped: dw.cnt.overwritten.Load() + dw.cnt.rejected.Load(),
+		Queued:  dw.cnt.queued(),
+	}
+}
+
+// Flush waits until all the events queued in the diode are written to the
+// wrapped writer or ctx is done, in which case ctx.Err() is returned.
+func (dw Writer) Flush(ctx context.Context) error {
+	if dw.cnt.queued() == 0 {
+		return nil
+	}
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for dw.cnt.queued() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Close drains the diode, releases the diode poller and call Close on the
+// wrapped writer if io.Closer is implemented.
 func (dw Writer) Close() error {
+	return dw.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but stops draining the diode once ctx is done.
+// Events still queued at this time are lost and ctx.Err() is returned
+// unless closing the wrapped writer fails.
+func (dw Writer) CloseContext(ctx context.Context) error {
+	err := dw.Flush(ctx)
 	dw.c()
 	<-dw.done
 	if w, ok := dw.w.(io.Closer); ok {
-		return w.Close()
+		if cerr := w.Close(); cerr != nil {
+			return cerr
+		}
 	}
-	return nil
+	return err
 }
 
 func (dw Writer) poll() {
 	defer close(dw.done)
+	if dw.opts.batchEvents > 0 || dw.opts.batchBytes > 0 {
+		dw.pollBatch()
+		return
+	}
 	for {
 		d := dw.d.Next()
 		if d == nil {
 			return
 		}
-		p := *(*[]byte)(d)
-		dw.w.Write(p)
-
-		// Proper usage of a sync.Pool requires each entry to have approximately
-		// the same memory cost. To obtain this property when the stored type
-		// contains a variably-sized buffer, we add a hard limit on the maximum buffer
-		// to place back in the pool.
-		//
-		// See https://golang.org/issue/23199
-		const maxSize = 1 << 16 // 64KiB
-		if cap(p) <= maxSize {
-			bufPool.Put(p[:0])
+		e := (*entry)(d)
+		if e.leveled && dw.lw != nil {
+			dw.lw.WriteLevel(e.level, e.p)
+		} else {
+			dw.w.Write(e.p)
+		}
+		dw.cnt.written.Add(1)
+		dw.cnt.signal()
+		putEntry(e)
+
+		if dw.ctx.Err() != nil {
+			// Closing: the diode has been drained or the deadline has passed.
+			return
+		}
+	}
+}
+
+// pollBatch is like poll but coalesces consecutive events into a single
+// write on the wrapped writer.
+func (dw Writer) pollBatch() {
+	var b batch
+	for {
+		d := dw.d.Next()
+		if d == nil {
+			return
 		}
+		b.add((*entry)(d))
+		deadline := time.Now().Add(dw.opts.batchLatency)
+		for !dw.batchFull(&b) {
+			d, ok := dw.d.TryNext()
+			if !ok {
+				wait := time.Until(deadline)
+				if wait <= 0 || dw.ctx.Err() != nil {
+					break
+				}
+				if wait > time.Millisecond {
+					wait = time.Millisecond
+				}
+				time.Sleep(wait)
+				continue
+			}
+			e := (*entry)(d)
+			if !dw.batchFits(&b, e) {
+				dw.writeBatch(&b)
+			}
+			b.add(e)
+		}
+		dw.writeBatch(&b)
+
+		if dw.ctx.Err() != nil {
+			// Closing: the diode has been drained or the deadline has passed.
+			return
+		}
+	}
+}
+
+// batch holds events to be written at once. Events of a batch share the same
+// level information.
+type batch struct {
+	p       []byte
+	n       int
+	level   zerolog.Level
+	leveled bool
+}
+
+func (b *batch) add(e *entry) {
+	if b.n == 0 {
+		b.level = e.level
+		b.leveled = e.leveled
+	}
+	b.p = append(b.p, e.p...)
+	b.n++
+	putEntry(e)
+}
+
+func (dw Writer) batchFull(b *batch) bool {
+	return (dw.opts.batchEvents > 0 && b.n >= dw.opts.batchEvents) ||
+		(dw.opts.batchBytes > 0 && len(b.p) >= dw.opts.batchBytes)
+}
+
+// batchFits reports whether e can be added to b without exceeding the byte
+// limit nor mixing levels if the wrapped writer is a zerolog.LevelWriter.
+func (dw Writer) batchFits(b *batch, e *entry) bool {
+	if b.n == 0 {
+		return true
+	}
+	if dw.opts.batchBytes > 0 && len(b.p)+len(e.p) > dw.opts.batchBytes {
+		return false
+	}
+	return dw.lw == nil || (b.leveled == e.leveled && b.level == e.level)
+}
+
+func (dw Writer) writeBatch(b *batch) {
+	if b.n == 0 {
+		return
+	}
+	if b.leveled && dw.lw != nil {
+		dw.lw.WriteLevel(b.level, b.p)
+	} else {
+		dw.w.Write(b.p)
+	}
+	dw.cnt.written.Add(uint64(b.n))
+	dw.cnt.signal()
+	b.n = 0
+	b.p = b.p[:0]
+}
+
+func putEntry(e *entry) {
+	// Proper usage of a sync.Pool requires each entry to have approximately
+	// the same memory cost. To obtain this property when the stored type
+	// contains a variably-sized buffer, we add a hard limit on the maximum buffer
+	// to place back in the pool.
+	//
+	// See https://golang.org/issue/23199
+	const maxSize = 1 << 16 // 64KiB
+	if cap(e.p) <= maxSize {
+		entryPool.Put(e)
 	}
 }
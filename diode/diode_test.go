@@ -2,12 +2,15 @@ package diode_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -40,6 +43,256 @@ func TestClose(t *testing.T) {
 	w.Close()
 }
 
+func TestStats(t *testing.T) {
+	w := diode.NewWriter(io.Discard, 1000, 0, nil)
+	log := zerolog.New(w)
+	for i := 0; i < 3; i++ {
+		log.Print("test")
+	}
+	w.Close()
+	if got, want := w.Stats(), (diode.Stats{Written: 3}); got != want {
+		t.Errorf("Invalid stats, got: %+v, want: %+v", got, want)
+	}
+}
+
+type gateWriter struct {
+	gate chan struct{}
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestBlockingLevel(t *testing.T) {
+	gw := &gateWriter{gate: make(chan struct{})}
+	var missed int
+	w := diode.NewWriter(gw, 2, 0, func(m int) { missed += m },
+		diode.WithBlockingLevel(zerolog.ErrorLevel, time.Second))
+	log := zerolog.New(w)
+	log.Error().Msg("1")
+	log.Error().Msg("2")
+	// The diode is full: debug events are dropped...
+	log.Debug().Msg("dropped")
+	if got := w.Stats(); got.Dropped != 1 || got.Queued != 2 {
+		t.Errorf("Invalid stats after drop: %+v", got)
+	}
+	// ... while error events wait for room.
+	done := make(chan struct{})
+	go func() {
+		log.Error().Msg("3")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Error event did not block")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(gw.gate)
+	<-done
+	w.Close()
+
+	want := `{"level":"error","message":"1"}
+{"level":"error","message":"2"}
+{"level":"error","message":"3"}
+`
+	if got := cbor.DecodeIfBinaryToString(gw.buf.Bytes()); got != want {
+		t.Errorf("Invalid output, got: %q, want: %q", got, want)
+	}
+	if got, want := w.Stats(), (diode.Stats{Written: 3, Dropped: 1}); got != want {
+		t.Errorf("Invalid stats, got: %+v, want: %+v", got, want)
+	}
+	if missed != 0 {
+		t.Errorf("Alerter called for rejected event, missed: %d", missed)
+	}
+}
+
+func TestBlockingLevelTimeout(t *testing.T) {
+	gw := &gateWriter{gate: make(chan struct{})}
+	w := diode.NewWriter(gw, 1, 0, nil, diode.WithBlockingLevel(zerolog.ErrorLevel, 10*time.Millisecond))
+	log := zerolog.New(w)
+	log.Error().Msg("1")
+	start := time.Now()
+	log.Error().Msg("2")
+	if d := time.Since(start); d < 10*time.Millisecond {
+		t.Errorf("Write returned before timeout: %s", d)
+	}
+	close(gw.gate)
+	w.Close()
+}
+
+type levelRecorder struct {
+	levels []zerolog.Level
+	writes int
+}
+
+func (w *levelRecorder) Write(p []byte) (int, error) {
+	w.writes++
+	return len(p), nil
+}
+
+func (w *levelRecorder) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	w.levels = append(w.levels, l)
+	return len(p), nil
+}
+
+func TestWriteLevel(t *testing.T) {
+	lr := &levelRecorder{}
+	w := diode.NewWriter(lr, 1000, 0, nil)
+	log := zerolog.New(w)
+	log.Info().Msg("")
+	log.Error().Msg("")
+	w.Write([]byte("raw\n"))
+	w.Close()
+	if want := []zerolog.Level{zerolog.InfoLevel, zerolog.ErrorLevel}; !reflect.DeepEqual(lr.levels, want) {
+		t.Errorf("Invalid levels, got: %v, want: %v", lr.levels, want)
+	}
+	if lr.writes != 1 {
+		t.Errorf("Expected 1 call to Write, got: %d", lr.writes)
+	}
+}
+
+type sleepWriter struct {
+	d time.Duration
+	n atomic.Int32
+}
+
+func (w *sleepWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.d)
+	w.n.Add(1)
+	return len(p), nil
+}
+
+func TestCloseDrains(t *testing.T) {
+	sw := &sleepWriter{d: time.Millisecond}
+	w := diode.NewWriter(sw, 1000, 10*time.Millisecond, nil)
+	log := zerolog.New(w)
+	for i := 0; i < 50; i++ {
+		log.Info().Msg("test")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := sw.n.Load(); n != 50 {
+		t.Errorf("Expected 50 events written, got: %d", n)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	sw := &sleepWriter{d: time.Millisecond}
+	w := diode.NewWriter(sw, 1000, 0, nil)
+	defer w.Close()
+	log := zerolog.New(w)
+	for i := 0; i < 20; i++ {
+		log.Info().Msg("test")
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := sw.n.Load(); n != 20 {
+		t.Errorf("Expected 20 events written, got: %d", n)
+	}
+}
+
+func TestCloseContextDeadline(t *testing.T) {
+	sw := &sleepWriter{d: 10 * time.Millisecond}
+	w := diode.NewWriter(sw, 1000, 0, nil)
+	log := zerolog.New(w)
+	for i := 0; i < 100; i++ {
+		log.Info().Msg("test")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := w.CloseContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded error, got: %v", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("CloseContext did not honor the deadline: %s", d)
+	}
+	if n := sw.n.Load(); n == 100 {
+		t.Error("Expected some events to be lost")
+	}
+}
+
+type callRecorder struct {
+	mu     sync.Mutex
+	calls  []string
+	levels []zerolog.Level
+}
+
+func (w *callRecorder) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls = append(w.calls, cbor.DecodeIfBinaryToString(p))
+	return len(p), nil
+}
+
+type levelCallRecorder struct {
+	callRecorder
+}
+
+func (w *levelCallRecorder) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	w.mu.Lock()
+	w.levels = append(w.levels, l)
+	w.mu.Unlock()
+	return w.Write(p)
+}
+
+func TestBatching(t *testing.T) {
+	cr := &callRecorder{}
+	w := diode.NewWriter(cr, 1000, 0, nil, diode.WithBatching(4, 0, 50*time.Millisecond))
+	log := zerolog.New(w)
+	for i := 0; i < 10; i++ {
+		log.Log().Int("i", i).Msg("")
+	}
+	w.Close()
+	want := []string{
+		"{\"i\":0}\n{\"i\":1}\n{\"i\":2}\n{\"i\":3}\n",
+		"{\"i\":4}\n{\"i\":5}\n{\"i\":6}\n{\"i\":7}\n",
+		"{\"i\":8}\n{\"i\":9}\n",
+	}
+	if !reflect.DeepEqual(cr.calls, want) {
+		t.Errorf("Invalid writes, got: %q, want: %q", cr.calls, want)
+	}
+	if got := w.Stats(); got.Written != 10 || got.Queued != 0 {
+		t.Errorf("Invalid stats: %+v", got)
+	}
+}
+
+func TestBatchingBytes(t *testing.T) {
+	cr := &callRecorder{}
+	w := diode.NewWriter(cr, 1000, 0, nil, diode.WithBatching(0, 21, 50*time.Millisecond))
+	w.Write([]byte("0123456789\n"))
+	w.Write([]byte("0123456789\n"))
+	w.Write([]byte("012345678\n"))
+	w.Close()
+	want := []string{"0123456789\n", "0123456789\n012345678\n"}
+	if !reflect.DeepEqual(cr.calls, want) {
+		t.Errorf("Invalid writes, got: %q, want: %q", cr.calls, want)
+	}
+}
+
+func TestBatchingLevels(t *testing.T) {
+	lr := &levelCallRecorder{}
+	w := diode.NewWriter(lr, 1000, 0, nil, diode.WithBatching(10, 0, 50*time.Millisecond))
+	log := zerolog.New(w)
+	log.Info().Msg("1")
+	log.Info().Msg("2")
+	log.Error().Msg("3")
+	w.Close()
+	if want := []zerolog.Level{zerolog.InfoLevel, zerolog.ErrorLevel}; !reflect.DeepEqual(lr.levels, want) {
+		t.Errorf("Invalid levels, got: %v, want: %v", lr.levels, want)
+	}
+	if len(lr.calls) != 2 {
+		t.Errorf("Expected 2 writes, got: %q", lr.calls)
+	}
+}
+
 func TestFatal(t *testing.T) {
 	if os.Getenv("TEST_FATAL") == "1" {
 		w := diode.NewWriter(os.Stderr, 1000, 0, func(missed int) {
@@ -72,12 +325,13 @@ func TestFatal(t *testing.T) {
 		}
 	}()
 
+	// The pipe must be fully read before calling Wait, which closes it.
+	wg.Wait() // Wait for the goroutine to finish copying
 	err = cmd.Wait()
 	if err == nil {
 		t.Error("Expected log.Fatal to exit with non-zero status")
 	}
 
-	wg.Wait() // Wait for the goroutine to finish copying
 	slurp := stderrBuf.Bytes()
 
 	want := "{\"level\":\"fatal\",\"message\":\"test\"}\n"
@@ -132,12 +386,13 @@ func TestFatalWithFilteredLevelWriter(t *testing.T) {
 		_, _ = io.Copy(&stdoutBuf, stdout)
 	}()
 
+	// The pipe must be fully read before calling Wait, which closes it.
+	wg.Wait() // Wait for the goroutine to finish copying
 	err = cmd.Wait()
 	if err == nil {
 		t.Error("Expected log.Fatal to exit with non-zero status")
 	}
 
-	wg.Wait() // Wait for the goroutine to finish copying
 	slurp := stdoutBuf.Bytes()
 
 	got := cbor.DecodeIfBinaryToString(slurp)
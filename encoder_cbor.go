@@ -14,6 +14,9 @@ var (
 	enc = cbor.Encoder{}
 )
 
+// binaryEncoding reports whether events are encoded in CBOR.
+const binaryEncoding = true
+
 func init() {
 	// using closure to reflect the changes at runtime.
 	cbor.JSONMarshalFunc = func(v interface{}) ([]byte, error) {
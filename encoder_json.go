@@ -16,6 +16,9 @@ var (
 	enc = json.Encoder{}
 )
 
+// binaryEncoding reports whether events are encoded in CBOR.
+const binaryEncoding = false
+
 func init() {
 	// using closure to reflect the changes at runtime.
 	json.JSONMarshalFunc = func(v interface{}) ([]byte, error) {
@@ -29,6 +29,7 @@ type Event struct {
 	ch        []Hook          // hooks from context
 	skipFrame int             // The number of additional frames to skip when printing the caller.
 	ctx       context.Context // Optional Go context for event
+	schema    *Schema         // Optional field names, global ones are used if nil
 }
 
 func putEvent(e *Event) {
@@ -39,6 +40,7 @@ func putEvent(e *Event) {
 	e.ch = nil
 	e.skipFrame = 0
 	e.ctx = nil
+	e.schema = nil
 	e.buf = e.buf[:0]
 
 	// Proper usage of a sync.Pool requires each entry to have approximately
@@ -75,6 +77,7 @@ func newEvent(w LevelWriter, level Level, stack bool, ctx context.Context, hooks
 	e.w = w
 	e.level = level
 	e.skipFrame = 0
+	e.schema = nil
 	return e
 }
 
@@ -85,7 +88,9 @@ func (e *Event) write() (err error) {
 	if e.level != Disabled {
 		e.buf = enc.AppendEndMarker(e.buf)
 		e.buf = enc.AppendLineBreak(e.buf)
-		if e.w != nil {
+		if cw, ok := e.w.(contextLevelWriter); ok && e.ctx != nil {
+			_, err = cw.writeLevelContext(e.ctx, e.level, e.buf)
+		} else if e.w != nil {
 			_, err = e.w.WriteLevel(e.level, e.buf)
 		}
 	}
@@ -148,11 +153,14 @@ func (e *Event) MsgFunc(createMsg func() string) {
 }
 
 func (e *Event) msg(msg string) {
+	if e.schema != nil && e.schema.Hook != nil {
+		e.schema.Hook.Run(e, e.level, msg)
+	}
 	for _, hook := range e.ch {
 		hook.Run(e, e.level, msg)
 	}
 	if msg != "" {
-		e.buf = enc.AppendString(enc.AppendKey(e.buf, MessageFieldName), msg)
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, e.schema.messageFieldName()), msg)
 	}
 	if e.done != nil {
 		defer e.done(msg)
@@ -173,7 +181,7 @@ func (e *Event) Fields(fields interface{}) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = appendFields(e.buf, fields, e.stack, e.ctx, e.ch)
+	e.buf = appendFields(e.buf, fields, e.stack, e.ctx, e.ch, e.schema)
 	return e
 }
 
@@ -464,21 +472,22 @@ func (e *Event) Err(err error) *Event {
 	}
 
 	if e.stack && ErrorStackMarshaler != nil {
+		stackFieldName := e.schema.errorStackFieldName()
 		switch m := ErrorStackMarshaler(err).(type) {
 		case nil:
 			return e
 		case LogObjectMarshaler:
-			e = e.Object(ErrorStackFieldName, m)
+			e = e.Object(stackFieldName, m)
 		case error:
-			e = e.Str(ErrorStackFieldName, m.Error())
+			e = e.Str(stackFieldName, m.Error())
 		case string:
-			e = e.Str(ErrorStackFieldName, m)
+			e = e.Str(stackFieldName, m)
 		default:
-			e = e.Interface(ErrorStackFieldName, m)
+			e = e.Interface(stackFieldName, m)
 		}
 	}
 
-	return e.AnErr(ErrorFieldName, err)
+	return e.AnErr(e.schema.errorFieldName(), err)
 }
 
 // Stack enables stack trace printing for the error passed to Err().
@@ -756,7 +765,7 @@ func (e *Event) Timestamp() *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendTime(enc.AppendKey(e.buf, TimestampFieldName), TimestampFunc(), TimeFieldFormat)
+	e.buf = enc.AppendTime(enc.AppendKey(e.buf, e.schema.timestampFieldName()), TimestampFunc(), TimeFieldFormat)
 	return e
 }
 
@@ -867,11 +876,21 @@ func (e *Event) caller(skip int) *Event {
 		return e
 	}
 	if pc, file, line, ok := runtime.Caller(skip + e.skipFrame); ok {
-		e.buf = enc.AppendString(enc.AppendKey(e.buf, CallerFieldName), CallerMarshalFunc(pc, file, line))
+		e.appendCaller(pc, file, line)
 	}
 	return e
 }
 
+// appendCaller adds the caller at pc, file and line to the event, as laid out
+// by the schema.
+func (e *Event) appendCaller(pc uintptr, file string, line int) {
+	if e.schema != nil && e.schema.CallerFunc != nil {
+		e.schema.CallerFunc(e, pc, file, line)
+		return
+	}
+	e.buf = enc.AppendString(enc.AppendKey(e.buf, e.schema.callerFieldName()), CallerMarshalFunc(pc, file, line))
+}
+
 // IPAddr adds the field key with ip as a net.IP IPv4 or IPv6 Address to the event
 func (e *Event) IPAddr(key string, ip net.IP) *Event {
 	if e == nil {
@@ -19,13 +19,13 @@ func isNilValue(e error) bool {
 	}
 }
 
-func appendFields(dst []byte, fields interface{}, stack bool, ctx context.Context, hooks []Hook) []byte {
+func appendFields(dst []byte, fields interface{}, stack bool, ctx context.Context, hooks []Hook, schema *Schema) []byte {
 	switch fields := fields.(type) {
 	case []interface{}:
 		if n := len(fields); n&0x1 == 1 { // odd number
 			fields = fields[:n-1]
 		}
-		dst = appendFieldList(dst, fields, stack, ctx, hooks)
+		dst = appendFieldList(dst, fields, stack, ctx, hooks, schema)
 	case map[string]interface{}:
 		keys := make([]string, 0, len(fields))
 		for key := range fields {
@@ -35,7 +35,7 @@ func appendFields(dst []byte, fields interface{}, stack bool, ctx context.Contex
 		kv := make([]interface{}, 2)
 		for _, key := range keys {
 			kv[0], kv[1] = key, fields[key]
-			dst = appendFieldList(dst, kv, stack, ctx, hooks)
+			dst = appendFieldList(dst, kv, stack, ctx, hooks, schema)
 		}
 	}
 	return dst
@@ -50,7 +50,7 @@ func appendObject(dst []byte, obj LogObjectMarshaler, stack bool, ctx context.Co
 	return dst
 }
 
-func appendFieldList(dst []byte, kvList []interface{}, stack bool, ctx context.Context, hooks []Hook) []byte {
+func appendFieldList(dst []byte, kvList []interface{}, stack bool, ctx context.Context, hooks []Hook, schema *Schema) []byte {
 	for i, n := 0, len(kvList); i < n; i += 2 {
 		key, val := kvList[i], kvList[i+1]
 		if key, ok := key.(string); ok {
@@ -84,16 +84,16 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool, ctx context.C
 				case nil:
 					return dst // do nothing with nil errors
 				case LogObjectMarshaler:
-					dst = enc.AppendKey(dst, ErrorStackFieldName)
+					dst = enc.AppendKey(dst, schema.errorStackFieldName())
 					dst = appendObject(dst, m, stack, ctx, hooks)
 				case error:
-					dst = enc.AppendKey(dst, ErrorStackFieldName)
+					dst = enc.AppendKey(dst, schema.errorStackFieldName())
 					dst = enc.AppendString(dst, m.Error())
 				case string:
-					dst = enc.AppendKey(dst, ErrorStackFieldName)
+					dst = enc.AppendKey(dst, schema.errorStackFieldName())
 					dst = enc.AppendString(dst, m)
 				default:
-					dst = enc.AppendKey(dst, ErrorStackFieldName)
+					dst = enc.AppendKey(dst, schema.errorStackFieldName())
 					dst = enc.AppendInterface(dst, m)
 				}
 			}
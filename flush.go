@@ -0,0 +1,158 @@
+package zerolog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Flusher is implemented by writers buffering events, like diode.Writer,
+// AsyncWriter, NetWriter or TriggerLevelWriter. Flush must write the buffered
+// events and return once done or once ctx is done.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+var flushers = struct {
+	sync.Mutex
+	next int
+	m    map[int]Flusher
+}{m: map[int]Flusher{}}
+
+// RegisterFlusher adds f to the writers flushed by Fatal, Panic and
+// FlushOnPanic before the program terminates, so buffered events are not
+// lost. The returned function removes f from the registered writers and must
+// be called before f is closed.
+//
+//	w := diode.NewWriter(os.Stdout, 1000, 0, nil)
+//	defer zerolog.RegisterFlusher(w)()
+func RegisterFlusher(f Flusher) (unregister func()) {
+	flushers.Lock()
+	id := flushers.next
+	flushers.next++
+	flushers.m[id] = f
+	flushers.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			flushers.Lock()
+			delete(flushers.m, id)
+			flushers.Unlock()
+		})
+	}
+}
+
+// Flush flushes all the registered writers concurrently and waits until they
+// are done or ctx is done, in which case ctx.Err() is returned. Errors
+// returned by the writers are joined.
+func Flush(ctx context.Context) error {
+	flushers.Lock()
+	fs := make([]Flusher, 0, len(flushers.m))
+	for _, f := range flushers.m {
+		fs = append(fs, f)
+	}
+	flushers.Unlock()
+	return flushAll(ctx, fs)
+}
+
+func flushAll(ctx context.Context, fs []Flusher) error {
+	if len(fs) == 0 {
+		return nil
+	}
+	errs := make([]error, len(fs))
+	var wg sync.WaitGroup
+	for i, f := range fs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = f.Flush(ctx)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return errors.Join(errs...)
+	case <-ctx.Done():
+		// Flushers ignoring ctx are abandoned: the program is about to
+		// terminate anyway.
+		return ctx.Err()
+	}
+}
+
+// flushBeforeExit flushes w, if it implements Flusher, and the registered
+// writers, then closes w if closeWriter is true, for at most FlushTimeout.
+// Flush errors are reported like write errors.
+func flushBeforeExit(w LevelWriter, closeWriter bool) {
+	ctx := context.Background()
+	if FlushTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, FlushTimeout)
+		defer cancel()
+	}
+	flushers.Lock()
+	fs := make([]Flusher, 0, len(flushers.m)+1)
+	if f, ok := w.(Flusher); ok {
+		fs = append(fs, f)
+	}
+	for _, f := range flushers.m {
+		fs = append(fs, f)
+	}
+	flushers.Unlock()
+	if err := flushAll(ctx, fs); err != nil {
+		if ErrorHandler != nil {
+			ErrorHandler(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "zerolog: could not flush writers: %v\n", err)
+		}
+	}
+	if closeWriter && ctx.Err() == nil {
+		closeContext(ctx, w)
+	}
+}
+
+// closeContext closes w, if it implements io.Closer, giving up once ctx is
+// done.
+func closeContext(ctx context.Context, w LevelWriter) {
+	if c, ok := w.(interface {
+		CloseContext(ctx context.Context) error
+	}); ok {
+		c.CloseContext(ctx)
+		return
+	}
+	closer, ok := w.(io.Closer)
+	if !ok {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		closer.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// FlushOnPanic flushes the registered writers for at most FlushTimeout when
+// the calling goroutine panics, then panics again with the same value. It must
+// be deferred directly, at the top of main and of the goroutines that may
+// panic:
+//
+//	func main() {
+//		defer zerolog.FlushOnPanic()
+//		...
+//	}
+func FlushOnPanic() {
+	if r := recover(); r != nil {
+		flushBeforeExit(nil, false)
+		panic(r)
+	}
+}
@@ -0,0 +1,174 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingFlusher struct {
+	bytes.Buffer
+	flushed atomic.Int32
+	err     error
+	block   bool
+}
+
+func (f *countingFlusher) Flush(ctx context.Context) error {
+	f.flushed.Add(1)
+	if f.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return f.err
+}
+
+func TestFlush(t *testing.T) {
+	f1, f2 := &countingFlusher{}, &countingFlusher{err: errors.New("flush failed")}
+	unregister1 := RegisterFlusher(f1)
+	unregister2 := RegisterFlusher(f2)
+
+	if err := Flush(context.Background()); err == nil || err.Error() != "flush failed" {
+		t.Errorf("Flush() error = %v, want flush failed", err)
+	}
+	if got := f1.flushed.Load(); got != 1 {
+		t.Errorf("first flusher flushed %d times, want 1", got)
+	}
+	if got := f2.flushed.Load(); got != 1 {
+		t.Errorf("second flusher flushed %d times, want 1", got)
+	}
+
+	unregister2()
+	unregister2()
+	if err := Flush(context.Background()); err != nil {
+		t.Errorf("Flush() error = %v, want nil", err)
+	}
+	if got := f2.flushed.Load(); got != 1 {
+		t.Errorf("unregistered flusher flushed %d times, want 1", got)
+	}
+	unregister1()
+	if got := f1.flushed.Load(); got != 2 {
+		t.Errorf("first flusher flushed %d times, want 2", got)
+	}
+}
+
+func TestFlushTimeout(t *testing.T) {
+	f := &countingFlusher{block: true}
+	defer RegisterFlusher(f)()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	oldTimeout, oldExit, oldHandler := FlushTimeout, FatalExitFunc, ErrorHandler
+	defer func() { FlushTimeout, FatalExitFunc, ErrorHandler = oldTimeout, oldExit, oldHandler }()
+	FlushTimeout = 10 * time.Millisecond
+	FatalExitFunc = func() {}
+	var flushErr error
+	ErrorHandler = func(err error) { flushErr = err }
+
+	start := time.Now()
+	log := New(&bytes.Buffer{})
+	log.Fatal().Msg("fatal")
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Fatal() took %v, want about %v", d, FlushTimeout)
+	}
+	if !errors.Is(flushErr, context.DeadlineExceeded) {
+		t.Errorf("reported error = %v, want %v", flushErr, context.DeadlineExceeded)
+	}
+}
+
+func TestFatalFlushes(t *testing.T) {
+	registered := &countingFlusher{}
+	defer RegisterFlusher(registered)()
+
+	oldExit := FatalExitFunc
+	defer func() { FatalExitFunc = oldExit }()
+	var flushedBeforeExit int32
+	out := &countingFlusher{}
+	FatalExitFunc = func() { flushedBeforeExit = registered.flushed.Load() + out.flushed.Load() }
+
+	log := New(out)
+	log.Fatal().Msg("fatal")
+	if flushedBeforeExit != 2 {
+		t.Errorf("%d writers flushed before exit, want 2", flushedBeforeExit)
+	}
+}
+
+func TestPanicFlushes(t *testing.T) {
+	registered := &countingFlusher{}
+	defer RegisterFlusher(registered)()
+
+	defer func() {
+		if r := recover(); r != "panic" {
+			t.Errorf("recovered %v, want panic", r)
+		}
+		if got := registered.flushed.Load(); got != 1 {
+			t.Errorf("registered writer flushed %d times, want 1", got)
+		}
+	}()
+	log := New(&bytes.Buffer{})
+	log.Panic().Msg("panic")
+}
+
+func TestFlushOnPanic(t *testing.T) {
+	registered := &countingFlusher{}
+	defer RegisterFlusher(registered)()
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Errorf("recovered %v, want boom", r)
+		}
+		if got := registered.flushed.Load(); got != 1 {
+			t.Errorf("registered writer flushed %d times, want 1", got)
+		}
+	}()
+	func() {
+		defer FlushOnPanic()
+		panic("boom")
+	}()
+}
+
+func TestFlushOnPanicNoPanic(t *testing.T) {
+	registered := &countingFlusher{}
+	defer RegisterFlusher(registered)()
+
+	func() {
+		defer FlushOnPanic()
+	}()
+	if got := registered.flushed.Load(); got != 0 {
+		t.Errorf("registered writer flushed %d times, want 0", got)
+	}
+}
+
+type blockingCloser struct {
+	bytes.Buffer
+	closed chan struct{}
+}
+
+func (w *blockingCloser) Close() error {
+	<-w.closed
+	return nil
+}
+
+func TestFatalCloseTimeout(t *testing.T) {
+	oldTimeout, oldExit := FlushTimeout, FatalExitFunc
+	defer func() { FlushTimeout, FatalExitFunc = oldTimeout, oldExit }()
+	FlushTimeout = 10 * time.Millisecond
+	exited := make(chan struct{})
+	FatalExitFunc = func() { close(exited) }
+
+	out := &blockingCloser{closed: make(chan struct{})}
+	defer close(out.closed)
+	log := New(out)
+	go log.Fatal().Msg("fatal")
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("Fatal() blocked on Close")
+	}
+}
@@ -0,0 +1,279 @@
+package zerolog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+
+	zjson "github.com/rs/zerolog/internal/json"
+)
+
+// GELFCompression defines the compression of GELF messages.
+type GELFCompression int
+
+const (
+	// GELFCompressNone sends uncompressed messages.
+	GELFCompressNone GELFCompression = iota
+	// GELFCompressGzip compresses messages with gzip.
+	GELFCompressGzip
+	// GELFCompressZlib compresses messages with zlib.
+	GELFCompressZlib
+)
+
+// DefaultGELFChunkSize is the default maximum size of GELF UDP datagrams,
+// chosen to fit in the MTU of most networks.
+const DefaultGELFChunkSize = 1420
+
+// gelfChunkHeaderSize is the size of the chunk magic bytes, message id,
+// sequence number and sequence count.
+const gelfChunkHeaderSize = 12
+
+// gelfMaxChunks is the maximum number of chunks of a GELF message.
+const gelfMaxChunks = 128
+
+// ErrGELFMessageTooLarge is returned by GELFWriter when a message needs more
+// than 128 chunks.
+var ErrGELFMessageTooLarge = errors.New("zerolog: GELF message too large")
+
+var gelfEnc = zjson.Encoder{}
+
+// GELFWriter is a LevelWriter formatting events as GELF 1.1 messages for
+// Graylog. The event message is sent as short_message, the error stack, if
+// any, as full_message, the level as a syslog severity and other fields as
+// additional fields prefixed with an underscore. Objects and arrays are sent
+// as JSON encoded strings.
+//
+// Each message, or chunk of message, is written with a single call to Out;
+// use NewGELFNetWriter to send messages to a Graylog input over UDP or TCP.
+type GELFWriter struct {
+	// Out is the destination writer.
+	Out io.Writer
+
+	// Host is the host field. Default is os.Hostname().
+	Host string
+
+	// Compression defines how messages are compressed. Compression is only
+	// supported by Graylog over UDP. Default is GELFCompressGzip if Out is a
+	// datagram NetWriter, GELFCompressNone otherwise.
+	Compression GELFCompression
+
+	// ChunkSize, if positive, is the maximum size of the writes to Out:
+	// larger messages are split in GELF chunks. Default is
+	// DefaultGELFChunkSize if Out is a datagram NetWriter, 0 otherwise.
+	ChunkSize int
+}
+
+// NewGELFWriter creates a GELFWriter writing to out.
+func NewGELFWriter(out io.Writer, options ...func(w *GELFWriter)) *GELFWriter {
+	hostname, _ := os.Hostname()
+	w := &GELFWriter{
+		Out:  out,
+		Host: hostname,
+	}
+	if nw, ok := out.(*NetWriter); ok && datagramNetwork(nw.Network) {
+		w.Compression = GELFCompressGzip
+		w.ChunkSize = DefaultGELFChunkSize
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// NewGELFNetWriter creates a NetWriter sending messages to a Graylog GELF
+// input, terminating messages with a null byte on stream networks and sending
+// one message per datagram otherwise.
+func NewGELFNetWriter(network, address string, options ...func(w *NetWriter)) *NetWriter {
+	framing := FramingNullByte
+	if datagramNetwork(network) {
+		framing = FramingNone
+	}
+	return NewNetWriter(network, address, func(w *NetWriter) {
+		w.Framing = framing
+		for _, opt := range options {
+			opt(w)
+		}
+	})
+}
+
+// Write implements the io.Writer interface. The level is read from the event
+// level field.
+func (w *GELFWriter) Write(p []byte) (n int, err error) {
+	return w.write(NoLevel, false, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *GELFWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(l, true, p)
+}
+
+func (w *GELFWriter) write(l Level, leveled bool, p []byte) (n int, err error) {
+	fields, err := decodeOrderedFields(decodeIfBinaryToBytes(p))
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	ts := time.Now()
+	var msg, full string
+	extra := make([]byte, 0, len(p)+16)
+	for _, f := range fields {
+		switch f.key {
+		case LevelFieldName:
+			if !leveled {
+				if s, ok := f.string(); ok {
+					l, _ = ParseLevel(s)
+				}
+			}
+			continue
+		case MessageFieldName:
+			msg, _ = f.string()
+			continue
+		case ErrorStackFieldName:
+			var ok bool
+			if full, ok = f.string(); !ok {
+				full = string(f.value)
+			}
+			continue
+		case TimestampFieldName:
+			if s, ok := f.string(); ok {
+				if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					ts = t
+					continue
+				}
+			}
+		}
+		if len(f.value) == 0 || string(f.value) == "null" {
+			continue
+		}
+		extra = append(extra, ',')
+		extra = gelfEnc.AppendString(extra, gelfFieldName(f.key))
+		extra = append(extra, ':')
+		switch f.value[0] {
+		case '"':
+			extra = append(extra, f.value...)
+		case '{', '[', 't', 'f':
+			extra = gelfEnc.AppendString(extra, string(f.value))
+		default:
+			extra = append(extra, f.value...)
+		}
+	}
+	if msg == "" {
+		// short_message is mandatory and can't be empty.
+		msg = "-"
+	}
+
+	buf := make([]byte, 0, len(extra)+len(msg)+len(full)+128)
+	buf = append(buf, `{"version":"1.1","host":`...)
+	buf = gelfEnc.AppendString(buf, w.Host)
+	buf = append(buf, `,"short_message":`...)
+	buf = gelfEnc.AppendString(buf, msg)
+	if full != "" {
+		buf = append(buf, `,"full_message":`...)
+		buf = gelfEnc.AppendString(buf, full)
+	}
+	buf = append(buf, `,"timestamp":`...)
+	buf = strconv.AppendFloat(buf, float64(ts.UnixMilli())/1e3, 'f', 3, 64)
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendInt(buf, int64(syslogSeverity(l)), 10)
+	buf = append(buf, extra...)
+	buf = append(buf, '}')
+
+	if buf, err = w.compress(buf); err != nil {
+		return 0, err
+	}
+	if err = w.send(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// gelfFieldName returns the additional field name of key: prefixed with an
+// underscore and with the characters not matching [\w.-] replaced by '_'.
+// The reserved _id field is renamed _id_.
+func gelfFieldName(key string) string {
+	name := make([]byte, 0, len(key)+2)
+	name = append(name, '_')
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '_', c == '.', c == '-':
+		default:
+			c = '_'
+		}
+		name = append(name, c)
+	}
+	if string(name) == "_id" {
+		name = append(name, '_')
+	}
+	return string(name)
+}
+
+func (w *GELFWriter) compress(p []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch w.Compression {
+	case GELFCompressGzip:
+		zw = gzip.NewWriter(&buf)
+	case GELFCompressZlib:
+		zw = zlib.NewWriter(&buf)
+	default:
+		return p, nil
+	}
+	if _, err := zw.Write(p); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// send writes p to Out, split in chunks if larger than ChunkSize.
+func (w *GELFWriter) send(p []byte) error {
+	if w.ChunkSize <= 0 || len(p) <= w.ChunkSize {
+		_, err := w.Out.Write(p)
+		return err
+	}
+	size := w.ChunkSize - gelfChunkHeaderSize
+	if size <= 0 {
+		return fmt.Errorf("zerolog: GELF chunk size must be greater than %d", gelfChunkHeaderSize)
+	}
+	count := (len(p) + size - 1) / size
+	if count > gelfMaxChunks {
+		return ErrGELFMessageTooLarge
+	}
+	id := rand.Uint64()
+	chunk := make([]byte, 0, w.ChunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(p) {
+			end = len(p)
+		}
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = binary.BigEndian.AppendUint64(chunk, id)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, p[i*size:end]...)
+		if _, err := w.Out.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close calls the Close method of Out if it implements io.Closer. Otherwise
+// does nothing.
+func (w *GELFWriter) Close() error {
+	if closer, ok := w.Out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package zerolog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingWriter records each write separately.
+type recordingWriter struct {
+	writes [][]byte
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func decodeGELF(t *testing.T, p []byte) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&m); err != nil {
+		t.Fatalf("invalid GELF message %q: %v", p, err)
+	}
+	return m
+}
+
+func TestGELFWriter(t *testing.T) {
+	rw := &recordingWriter{}
+	w := NewGELFWriter(rw, func(w *GELFWriter) {
+		w.Host = "host"
+	})
+	event := `{"level":"error","time":"2024-01-02T03:04:05.123Z","id":"x","a b":"c","n":1.5,"ok":true,"o":{"x":1},"nil":null,"stack":[{"func":"f"}],"message":"failed"}`
+	if _, err := w.Write([]byte(event + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := decodeGELF(t, rw.writes[0])
+	want := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "host",
+		"short_message": "failed",
+		"full_message":  `[{"func":"f"}]`,
+		"timestamp":     json.Number("1704164645.123"),
+		"level":         json.Number("3"),
+		"_id_":          "x",
+		"_a_b":          "c",
+		"_n":            json.Number("1.5"),
+		"_ok":           "true",
+		"_o":            `{"x":1}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid message:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestGELFWriterLevel(t *testing.T) {
+	rw := &recordingWriter{}
+	log := New(NewGELFWriter(rw))
+	log.Warn().Msg("")
+	got := decodeGELF(t, rw.writes[0])
+	if got["level"] != json.Number("4") || got["short_message"] != "-" {
+		t.Errorf("invalid message: %v", got)
+	}
+}
+
+func TestGELFWriterChunking(t *testing.T) {
+	rw := &recordingWriter{}
+	w := NewGELFWriter(rw, func(w *GELFWriter) {
+		w.ChunkSize = 112
+	})
+	msg := strings.Repeat("x", 500)
+	if _, err := w.Write([]byte(`{"message":"` + msg + `"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(rw.writes) < 2 {
+		t.Fatalf("expected chunks, got %d writes", len(rw.writes))
+	}
+	var full []byte
+	id := binary.BigEndian.Uint64(rw.writes[0][2:10])
+	for i, chunk := range rw.writes {
+		if len(chunk) > 112 {
+			t.Errorf("chunk %d too large: %d", i, len(chunk))
+		}
+		if chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Errorf("invalid chunk %d magic bytes: %x", i, chunk[:2])
+		}
+		if binary.BigEndian.Uint64(chunk[2:10]) != id {
+			t.Errorf("invalid chunk %d message id", i)
+		}
+		if int(chunk[10]) != i || int(chunk[11]) != len(rw.writes) {
+			t.Errorf("invalid chunk %d sequence: %d/%d", i, chunk[10], chunk[11])
+		}
+		full = append(full, chunk[12:]...)
+	}
+	if got := decodeGELF(t, full)["short_message"]; got != msg {
+		t.Errorf("invalid reassembled message: %v", got)
+	}
+
+	w.ChunkSize = 13
+	if _, err := w.Write([]byte(`{"message":"` + msg + `"}`)); err != ErrGELFMessageTooLarge {
+		t.Errorf("expected ErrGELFMessageTooLarge, got: %v", err)
+	}
+}
+
+func TestGELFNetWriterUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w := NewGELFWriter(NewGELFNetWriter("udp", pc.LocalAddr().String()))
+	defer w.Close()
+	if w.Compression != GELFCompressGzip || w.ChunkSize != DefaultGELFChunkSize {
+		t.Errorf("invalid UDP defaults: %d, %d", w.Compression, w.ChunkSize)
+	}
+	w.Write([]byte(`{"message":"hello"}`))
+
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 2048)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(buf[:n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeGELF(t, p)["short_message"]; got != "hello" {
+		t.Errorf("invalid message: %v", got)
+	}
+
+	if w := NewGELFNetWriter("tcp", "127.0.0.1:12201"); w.Framing != FramingNullByte {
+		t.Errorf("invalid TCP framing: %d", w.Framing)
+	}
+}
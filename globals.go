@@ -72,6 +72,10 @@ var (
 	// CallerFieldName is the field name used for caller field.
 	CallerFieldName = "caller"
 
+	// LoggerNameFieldName is the field name used for the name of the loggers
+	// created with Logger.Named. No field is added if empty.
+	LoggerNameFieldName = "logger"
+
 	// CallerSkipFrameCount is the number of stack frames to skip to find the caller.
 	CallerSkipFrameCount = 2
 
@@ -138,6 +142,11 @@ var (
 	// os.Exit(1) is called.
 	FatalExitFunc func()
 
+	// FlushTimeout is the maximum time spent by Fatal, Panic and FlushOnPanic
+	// flushing the registered writers (see RegisterFlusher) before the
+	// program terminates. A zero value means no timeout.
+	FlushTimeout = 5 * time.Second
+
 	// DefaultContextLogger is returned from Ctx() if there is no logger associated
 	// with the context.
 	DefaultContextLogger *Logger
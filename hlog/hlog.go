@@ -3,8 +3,13 @@ package hlog
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"math/rand"
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 	"time"
 
@@ -47,6 +52,45 @@ func URLHandler(fieldKey string) func(next http.Handler) http.Handler {
 	}
 }
 
+// QueryMode defines how URLQueryHandler logs the query string of URLs.
+type QueryMode int
+
+const (
+	// QueryKeep logs the query string as is.
+	QueryKeep QueryMode = iota
+	// QueryStrip removes the query string.
+	QueryStrip
+	// QueryHash replaces the query string with a short hash of its value,
+	// which keeps requests with the same query correlatable without logging
+	// its content.
+	QueryHash
+)
+
+// URLQueryHandler is similar to URLHandler but lets control how the query
+// string of the URL is logged using mode.
+func URLQueryHandler(fieldKey string, mode QueryMode) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u := *r.URL
+			switch mode {
+			case QueryStrip:
+				u.RawQuery = ""
+				u.ForceQuery = false
+			case QueryHash:
+				if u.RawQuery != "" {
+					sum := sha256.Sum256([]byte(u.RawQuery))
+					u.RawQuery = hex.EncodeToString(sum[:8])
+				}
+			}
+			log := zerolog.Ctx(r.Context())
+			log.UpdateContext(func(c zerolog.Context) zerolog.Context {
+				return c.Str(fieldKey, u.String())
+			})
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // MethodHandler adds the request method as a field to the context's logger
 // using fieldKey as field key.
 func MethodHandler(fieldKey string) func(next http.Handler) http.Handler {
@@ -324,3 +368,153 @@ func HostHandler(fieldKey string, trimPort ...bool) func(next http.Handler) http
 		})
 	}
 }
+
+// LevelSelector returns the level to set on a request's logger. If ok is
+// false, the logger is left untouched.
+type LevelSelector func(r *http.Request) (level zerolog.Level, ok bool)
+
+// LevelHandler sets the level of the request's logger to the level returned
+// by s and disables sampling for this request only, which allows to enable
+// debug logging for a single request. The handler must be installed after
+// NewHandler: the request's logger is changed in place, so the level also
+// applies to the handlers installed before, like AccessHandler. Note that the
+// global level (see zerolog.SetGlobalLevel) still applies.
+func LevelHandler(s LevelSelector) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if lvl, ok := s(r); ok {
+				ctx := r.Context()
+				l := zerolog.Ctx(ctx)
+				if l == zerolog.DefaultContextLogger || l.GetLevel() == zerolog.Disabled {
+					// Not a request's logger: leave the shared logger untouched.
+					rl := l.Level(lvl).Sample(nil)
+					r = r.WithContext(rl.WithContext(ctx))
+				} else {
+					*l = l.Level(lvl).Sample(nil)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HeaderLevel returns a LevelSelector parsing the level from the headerName
+// request header (e.g. "X-Debug-Log: debug"). Requests without the header or
+// with an invalid level are ignored.
+//
+// The selector should be guarded using RequireSecret or RequireRemoteIP as
+// it lets clients control the verbosity of the server.
+func HeaderLevel(headerName string) LevelSelector {
+	return func(r *http.Request) (zerolog.Level, bool) {
+		v := r.Header.Get(headerName)
+		if v == "" {
+			return zerolog.NoLevel, false
+		}
+		lvl, err := zerolog.ParseLevel(v)
+		if err != nil || lvl == zerolog.NoLevel {
+			return zerolog.NoLevel, false
+		}
+		return lvl, true
+	}
+}
+
+// RequireSecret returns a LevelSelector calling s only if the headerName
+// request header is set to secret.
+func RequireSecret(headerName, secret string, s LevelSelector) LevelSelector {
+	return func(r *http.Request) (zerolog.Level, bool) {
+		v := r.Header.Get(headerName)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(v), []byte(secret)) != 1 {
+			return zerolog.NoLevel, false
+		}
+		return s(r)
+	}
+}
+
+// RequireRemoteIP returns a LevelSelector calling s only if the request's
+// remote IP is part of one of the allowed prefixes.
+func RequireRemoteIP(allowed []netip.Prefix, s LevelSelector) LevelSelector {
+	return func(r *http.Request) (zerolog.Level, bool) {
+		ip, err := netip.ParseAddr(getHost(r.RemoteAddr))
+		if err != nil {
+			return zerolog.NoLevel, false
+		}
+		ip = ip.Unmap()
+		for _, p := range allowed {
+			if p.Contains(ip) {
+				return s(r)
+			}
+		}
+		return zerolog.NoLevel, false
+	}
+}
+
+// SampledLevel returns a LevelSelector selecting level for the given
+// fraction (between 0 and 1) of requests.
+func SampledLevel(level zerolog.Level, fraction float64) LevelSelector {
+	return func(r *http.Request) (zerolog.Level, bool) {
+		if fraction <= 0 || rand.Float64() >= fraction {
+			return zerolog.NoLevel, false
+		}
+		return level, true
+	}
+}
+
+// RouteHandler adds the pattern of the http.ServeMux route matching the
+// request (see http.Request.Pattern) as a field to the context's logger
+// using patternKey as field key, and the values of the pattern's wildcards
+// as a dict using valuesKey as field key. Empty keys disable the
+// corresponding field.
+//
+// Unlike the raw URL, the pattern has a bounded cardinality. As the pattern
+// is only known once the request is routed, the handler must wrap the
+// handlers registered on the mux:
+//
+//	route := hlog.RouteHandler("route", "params")
+//	mux.Handle("GET /users/{id}", route(usersHandler))
+func RouteHandler(patternKey, valuesKey string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Pattern != "" {
+				log := zerolog.Ctx(r.Context())
+				log.UpdateContext(func(c zerolog.Context) zerolog.Context {
+					if patternKey != "" {
+						c = c.Str(patternKey, r.Pattern)
+					}
+					if valuesKey != "" {
+						if names := patternWildcards(r.Pattern); len(names) > 0 {
+							d := zerolog.Dict()
+							for _, name := range names {
+								d.Str(name, r.PathValue(name))
+							}
+							c = c.Dict(valuesKey, d)
+						}
+					}
+					return c
+				})
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// patternWildcards returns the names of the wildcards of a http.ServeMux
+// pattern in order of appearance.
+func patternWildcards(pattern string) []string {
+	var names []string
+	for {
+		i := strings.IndexByte(pattern, '{')
+		if i < 0 {
+			return names
+		}
+		pattern = pattern[i+1:]
+		j := strings.IndexByte(pattern, '}')
+		if j < 0 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[:j], "...")
+		pattern = pattern[j+1:]
+		if name != "" && name != "$" {
+			names = append(names, name)
+		}
+	}
+}
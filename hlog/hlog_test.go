@@ -10,9 +10,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/rs/xid"
 	"github.com/rs/zerolog"
@@ -57,6 +59,35 @@ func TestURLHandler(t *testing.T) {
 	}
 }
 
+func TestURLQueryHandler(t *testing.T) {
+	tests := []struct {
+		mode QueryMode
+		want string
+	}{
+		{QueryKeep, `{"url":"/path?foo=bar"}` + "\n"},
+		{QueryStrip, `{"url":"/path"}` + "\n"},
+		{QueryHash, `{"url":"/path?3ba8907e7a252327"}` + "\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		r := &http.Request{
+			URL: &url.URL{Path: "/path", RawQuery: "foo=bar"},
+		}
+		h := URLQueryHandler("url", tt.mode)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := FromRequest(r)
+			l.Log().Msg("")
+		}))
+		h = NewHandler(zerolog.New(out))(h)
+		h.ServeHTTP(nil, r)
+		if got := decodeIfBinary(out); got != tt.want {
+			t.Errorf("Invalid log output for mode %d, got: %s, want: %s", tt.mode, got, tt.want)
+		}
+		if r.URL.RawQuery != "foo=bar" {
+			t.Errorf("Request URL modified: %s", r.URL)
+		}
+	}
+}
+
 func TestMethodHandler(t *testing.T) {
 	out := &bytes.Buffer{}
 	r := &http.Request{
@@ -433,3 +464,134 @@ func TestGetHost(t *testing.T) {
 		})
 	}
 }
+
+func TestLevelHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	sel := RequireSecret("X-Debug-Secret", "s3cr3t", HeaderLevel("X-Debug-Log"))
+	h := LevelHandler(sel)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Debug().Msg("")
+	}))
+	h = NewHandler(zerolog.New(out).Level(zerolog.InfoLevel).Sample(zerolog.RandomSampler(0)))(h)
+
+	tests := []struct {
+		header http.Header
+		want   string
+	}{
+		{http.Header{}, ""},
+		{http.Header{"X-Debug-Log": []string{"debug"}}, ""},
+		{http.Header{"X-Debug-Log": []string{"debug"}, "X-Debug-Secret": []string{"wrong"}}, ""},
+		{http.Header{"X-Debug-Log": []string{"invalid"}, "X-Debug-Secret": []string{"s3cr3t"}}, ""},
+		{http.Header{"X-Debug-Log": []string{"debug"}, "X-Debug-Secret": []string{"s3cr3t"}}, `{"level":"debug"}` + "\n"},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		h.ServeHTTP(nil, &http.Request{Header: tt.header})
+		if got := decodeIfBinary(out); got != tt.want {
+			t.Errorf("Invalid log output for %v, got: %s, want: %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLevelHandlerAccessHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := LevelHandler(HeaderLevel("X-Debug-Log"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromRequest(r).UpdateContext(func(c zerolog.Context) zerolog.Context {
+			return c.Str("route", "/users")
+		})
+	}))
+	h = AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
+		FromRequest(r).Debug().Msg("access")
+	})(h)
+	h = NewHandler(zerolog.New(out).Level(zerolog.InfoLevel))(h)
+
+	h.ServeHTTP(httptest.NewRecorder(), &http.Request{Header: http.Header{}})
+	if got := decodeIfBinary(out); got != "" {
+		t.Errorf("Unexpected log output: %s", got)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), &http.Request{Header: http.Header{"X-Debug-Log": []string{"debug"}}})
+	if got, want := decodeIfBinary(out), `{"level":"debug","route":"/users","message":"access"}`+"\n"; got != want {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
+
+func TestLevelHandlerNamed(t *testing.T) {
+	zerolog.SetNamedLevel("http", zerolog.InfoLevel)
+	defer zerolog.SetNamedLevel("http", zerolog.NoLevel)
+
+	out := &bytes.Buffer{}
+	h := LevelHandler(HeaderLevel("X-Debug-Log"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromRequest(r).Debug().Msg("")
+	}))
+	h = NewHandler(zerolog.New(out).Named("http"))(h)
+
+	h.ServeHTTP(nil, &http.Request{Header: http.Header{}})
+	if got := decodeIfBinary(out); got != "" {
+		t.Errorf("Unexpected log output: %s", got)
+	}
+	h.ServeHTTP(nil, &http.Request{Header: http.Header{"X-Debug-Log": []string{"debug"}}})
+	if got, want := decodeIfBinary(out), `{"level":"debug","logger":"http"}`+"\n"; got != want {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
+
+func TestRequireRemoteIP(t *testing.T) {
+	sel := RequireRemoteIP([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, SampledLevel(zerolog.TraceLevel, 1))
+	tests := []struct {
+		remoteAddr string
+		ok         bool
+	}{
+		{"10.1.2.3:1234", true},
+		{"[::ffff:10.1.2.3]:1234", true},
+		{"192.168.1.1:1234", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		lvl, ok := sel(&http.Request{RemoteAddr: tt.remoteAddr})
+		if ok != tt.ok || (ok && lvl != zerolog.TraceLevel) {
+			t.Errorf("RequireRemoteIP(%q) = %v, %v", tt.remoteAddr, lvl, ok)
+		}
+	}
+}
+
+func TestSampledLevel(t *testing.T) {
+	if _, ok := SampledLevel(zerolog.DebugLevel, 0)(&http.Request{}); ok {
+		t.Error("SampledLevel with fraction 0 must never select")
+	}
+	if lvl, ok := SampledLevel(zerolog.DebugLevel, 1)(&http.Request{}); !ok || lvl != zerolog.DebugLevel {
+		t.Error("SampledLevel with fraction 1 must always select")
+	}
+}
+
+func TestRouteHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	route := RouteHandler("route", "params")
+	mux := http.NewServeMux()
+	mux.Handle("GET /users/{id}/files/{path...}", route(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Log().Msg("")
+	})))
+	h := NewHandler(zerolog.New(out))(mux)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42/files/a/b.txt", nil))
+	want := `{"route":"GET /users/{id}/files/{path...}","params":{"id":"42","path":"a/b.txt"}}` + "\n"
+	if got := decodeIfBinary(out); got != want {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
+
+func TestPatternWildcards(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/", nil},
+		{"/{$}", nil},
+		{"GET example.com/a/{x}/b/{y}", []string{"x", "y"}},
+		{"/files/{path...}", []string{"path"}},
+	}
+	for _, tt := range tests {
+		if got := patternWildcards(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("patternWildcards(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
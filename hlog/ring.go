@@ -0,0 +1,55 @@
+package hlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/internal/cbor"
+)
+
+// RingHandler returns an http.Handler dumping the events kept by ring, one
+// JSON event per line, oldest first, to be served at /debug/logs for
+// instance.
+//
+// The events can be filtered with the level query parameter, selecting the
+// events at this level or above, and field parameters of the form key:value,
+// selecting the events with a key field equal to value. The value is
+// compared as JSON if valid, as a string otherwise:
+//
+//	/debug/logs?level=warn&field=status:500&field=user:bob
+//
+// The handler must not be exposed publicly.
+func RingHandler(ring *zerolog.RingWriter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var filters []zerolog.RingFilter
+		q := r.URL.Query()
+		if s := q.Get("level"); s != "" {
+			l, err := zerolog.ParseLevel(s)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid level: %q", s), http.StatusBadRequest)
+				return
+			}
+			filters = append(filters, zerolog.RingLevelFilter(l))
+		}
+		for _, f := range q["field"] {
+			key, s, ok := strings.Cut(f, ":")
+			if !ok {
+				http.Error(w, fmt.Sprintf("invalid field: %q", f), http.StatusBadRequest)
+				return
+			}
+			var value interface{} = s
+			if json.Valid([]byte(s)) {
+				value = json.RawMessage(s)
+			}
+			filters = append(filters, zerolog.RingFieldFilter(key, value))
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, p := range ring.Events(filters...) {
+			w.Write(cbor.DecodeIfBinaryToBytes(p))
+		}
+	})
+}
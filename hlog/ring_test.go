@@ -0,0 +1,43 @@
+package hlog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRingHandler(t *testing.T) {
+	ring := zerolog.NewRingWriter()
+	log := zerolog.New(ring)
+	log.Debug().Int("status", 200).Msg("a")
+	log.Warn().Int("status", 500).Str("user", "bob").Msg("b")
+	log.Error().Int("status", 500).Msg("c")
+
+	h := RingHandler(ring)
+	tests := []struct {
+		query string
+		code  int
+		body  string
+	}{
+		{"", http.StatusOK, `{"level":"debug","status":200,"message":"a"}` + "\n" +
+			`{"level":"warn","status":500,"user":"bob","message":"b"}` + "\n" +
+			`{"level":"error","status":500,"message":"c"}` + "\n"},
+		{"?level=error", http.StatusOK, `{"level":"error","status":500,"message":"c"}` + "\n"},
+		{"?field=status:500&field=user:bob", http.StatusOK, `{"level":"warn","status":500,"user":"bob","message":"b"}` + "\n"},
+		{"?level=verbose", http.StatusBadRequest, `invalid level: "verbose"` + "\n"},
+		{"?field=status", http.StatusBadRequest, `invalid field: "status"` + "\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/logs"+tt.query, nil))
+		if got := w.Body.String(); w.Code != tt.code || got != tt.body {
+			t.Errorf("%s: got %d %s, want %d %s", tt.query, w.Code, got, tt.code, tt.body)
+		}
+		if tt.code == http.StatusOK && !strings.HasPrefix(w.Header().Get("Content-Type"), "application/x-ndjson") {
+			t.Errorf("invalid content type: %s", w.Header().Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package hlog
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type retryKey struct{}
+
+// CtxWithRetry adds the retry count of an outgoing request to the context.
+// Retrying clients should set it on each new attempt so Transport can log it.
+func CtxWithRetry(ctx context.Context, retry int) context.Context {
+	return context.WithValue(ctx, retryKey{}, retry)
+}
+
+// RetryFromCtx returns the retry count associated to the context if any.
+func RetryFromCtx(ctx context.Context) (retry int, ok bool) {
+	retry, ok = ctx.Value(retryKey{}).(int)
+	return
+}
+
+// Transport is an http.RoundTripper logging outgoing requests with the
+// logger associated to the request's context (see zerolog.Ctx). Each request
+// is logged once its response headers are received or it failed, with its
+// method, URL (with its password redacted), status, duration, retry count
+// and error if any.
+type Transport struct {
+	// Base is the RoundTripper used to perform the requests. If nil,
+	// http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// RequestIDHeader, if not empty, is the header used to forward the
+	// request id found in the request's context (see IDFromCtx) to the
+	// downstream service. An already set header is left untouched.
+	RequestIDHeader string
+
+	// Message is the message of the logged events. If empty, "http request"
+	// is used.
+	Message string
+
+	// LevelFunc returns the level used to log a request. If nil, requests
+	// failing with an error are logged at error level, 5xx responses at warn
+	// level and other responses at info level.
+	LevelFunc func(resp *http.Response, err error) zerolog.Level
+}
+
+// NewTransport returns a Transport wrapping base which forwards the request
+// id using the headerName header. An empty headerName disables forwarding.
+func NewTransport(base http.RoundTripper, headerName string) *Transport {
+	return &Transport{Base: base, RequestIDHeader: headerName}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx := r.Context()
+	if t.RequestIDHeader != "" && r.Header.Get(t.RequestIDHeader) == "" {
+		if id, ok := IDFromCtx(ctx); ok {
+			// RoundTrippers must not modify the request, work on a copy.
+			r = r.Clone(ctx)
+			r.Header.Set(t.RequestIDHeader, id.String())
+		}
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(r)
+	duration := time.Since(start)
+
+	level := t.level(resp, err)
+	e := zerolog.Ctx(ctx).WithLevel(level)
+	if !e.Enabled() {
+		return resp, err
+	}
+	e.Str("method", r.Method).
+		Str("url", r.URL.Redacted())
+	if resp != nil {
+		e.Int("status", resp.StatusCode)
+	}
+	e.Dur("duration", duration)
+	if retry, ok := RetryFromCtx(ctx); ok {
+		e.Int("retry", retry)
+	}
+	if err != nil {
+		e.Err(err)
+	}
+	msg := t.Message
+	if msg == "" {
+		msg = "http request"
+	}
+	e.Msg(msg)
+	return resp, err
+}
+
+func (t *Transport) level(resp *http.Response, err error) zerolog.Level {
+	if t.LevelFunc != nil {
+		return t.LevelFunc(resp, err)
+	}
+	switch {
+	case err != nil:
+		return zerolog.ErrorLevel
+	case resp != nil && resp.StatusCode >= 500:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
@@ -42,6 +42,10 @@ var SendFunc func(string, journal.Priority, map[string]string) error
 // to be used as parameter to New() calls. Writing logs
 // to this writer will send the log messages to journalD
 // running in this system.
+//
+// The writer has no options: flattening of nested objects, custom level
+// priorities, SYSLOG_IDENTIFIER and CODE_FILE/CODE_LINE are only supported
+// by Writer (see NewWriter), which should be preferred.
 func NewJournalDWriter() io.Writer {
 	return journalWriter{}
 }
@@ -54,7 +58,12 @@ type journalWriter struct {
 // priorities than zerolog.
 func levelToJPrio(zLevel string) journal.Priority {
 	lvl, _ := zerolog.ParseLevel(zLevel)
+	return levelPriority(lvl)
+}
 
+// levelPriority converts a zerolog Level into journalD's
+// priority value.
+func levelPriority(lvl zerolog.Level) journal.Priority {
 	switch lvl {
 	case zerolog.TraceLevel:
 		return journal.PriDebug
@@ -0,0 +1,303 @@
+//go:build linux
+// +build linux
+
+package journald
+
+// This file provides a journald writer speaking the native journal protocol
+// directly, without going through go-systemd.
+//
+// See https://systemd.io/JOURNAL_NATIVE_PROTOCOL/
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/coreos/go-systemd/v22/journal"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/internal/cbor"
+	"golang.org/x/sys/unix"
+)
+
+// DefaultSocketPath is the path of the journald native protocol socket.
+const DefaultSocketPath = "/run/systemd/journal/socket"
+
+var entryPool = &sync.Pool{
+	New: func() interface{} {
+		return &entry{buf: make([]byte, 0, 1024)}
+	},
+}
+
+type entry struct {
+	buf []byte
+}
+
+// Writer is a zerolog.LevelWriter sending events to journald using its native
+// protocol. Top level fields are sent as journald fields, with the same
+// conventions as NewJournalDWriter, but the event is streamed field by field
+// instead of being decoded into a map.
+//
+// Entries too large to fit in a datagram are sent through a sealed memfd.
+type Writer struct {
+	// SocketPath is the path of the journald socket. Default is
+	// DefaultSocketPath.
+	SocketPath string
+
+	// FlattenFields, if true, sends the fields of nested objects as separate
+	// journald fields named PARENT_CHILD instead of a single JSON encoded
+	// field.
+	FlattenFields bool
+
+	// Priorities overrides the journald priority of some levels, including
+	// custom levels. Levels not in the map use the default mapping.
+	Priorities map[zerolog.Level]journal.Priority
+
+	// SyslogIdentifier, if not empty, is sent as the SYSLOG_IDENTIFIER field.
+	SyslogIdentifier string
+
+	// CodeLocation, if true, sends the caller field (see zerolog.Caller) as
+	// the CODE_FILE and CODE_LINE fields.
+	CodeLocation bool
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewWriter creates a Writer. The socket is opened on the first write.
+func NewWriter(options ...func(w *Writer)) *Writer {
+	w := &Writer{
+		SocketPath: DefaultSocketPath,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements the io.Writer interface. The priority is read from the
+// event level field.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.write(zerolog.NoLevel, false, p)
+}
+
+// WriteLevel implements the zerolog.LevelWriter interface.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	return w.write(level, true, p)
+}
+
+func (w *Writer) write(level zerolog.Level, leveled bool, p []byte) (n int, err error) {
+	e := entryPool.Get().(*entry)
+	defer putEntry(e)
+
+	origPLen := len(p)
+	p = cbor.DecodeIfBinaryToBytes(p)
+	if e.buf, err = w.appendEntry(e.buf[:0], level, leveled, p); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	if err = w.send(e.buf); err != nil {
+		return 0, err
+	}
+	return origPLen, nil
+}
+
+// appendEntry appends the journald fields of the JSON event p to dst.
+func (w *Writer) appendEntry(dst []byte, level zerolog.Level, leveled bool, p []byte) ([]byte, error) {
+	err := eachField(p, func(key string, value json.RawMessage) (err error) {
+		switch key {
+		case zerolog.LevelFieldName:
+			if !leveled {
+				var s string
+				json.Unmarshal(value, &s)
+				level, _ = zerolog.ParseLevel(s)
+			}
+			return nil
+		case zerolog.TimestampFieldName:
+			return nil
+		case zerolog.MessageFieldName:
+			var s string
+			json.Unmarshal(value, &s)
+			dst = appendField(dst, "MESSAGE", s)
+			return nil
+		case zerolog.CallerFieldName:
+			if w.CodeLocation {
+				var s string
+				json.Unmarshal(value, &s)
+				file, line := s, ""
+				if i := strings.LastIndexByte(s, ':'); i > 0 {
+					file, line = s[:i], s[i+1:]
+				}
+				dst = appendField(dst, "CODE_FILE", file)
+				if line != "" {
+					dst = appendField(dst, "CODE_LINE", line)
+				}
+				return nil
+			}
+		}
+		dst, err = w.appendValue(dst, key, value)
+		return err
+	})
+	if err != nil {
+		return dst, err
+	}
+	if w.SyslogIdentifier != "" {
+		dst = appendField(dst, "SYSLOG_IDENTIFIER", w.SyslogIdentifier)
+	}
+	dst = appendField(dst, "PRIORITY", strconv.Itoa(int(w.priority(level))))
+	dst = appendField(dst, "JSON", string(p))
+	return dst, nil
+}
+
+// appendValue appends the field key with the given JSON value to dst,
+// flattening objects if FlattenFields is set.
+func (w *Writer) appendValue(dst []byte, key string, value json.RawMessage) (_ []byte, err error) {
+	switch {
+	case len(value) > 0 && value[0] == '"':
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return dst, err
+		}
+		return appendField(dst, sanitizeKey(key), s), nil
+	case len(value) > 0 && value[0] == '{' && w.FlattenFields:
+		err = eachField(value, func(child string, value json.RawMessage) (err error) {
+			dst, err = w.appendValue(dst, key+"_"+child, value)
+			return err
+		})
+		return dst, err
+	default:
+		return appendField(dst, sanitizeKey(key), string(value)), nil
+	}
+}
+
+func (w *Writer) priority(level zerolog.Level) journal.Priority {
+	if p, ok := w.Priorities[level]; ok {
+		return p
+	}
+	return levelPriority(level)
+}
+
+// eachField calls fn for each field of the JSON object p, in order.
+func eachField(p []byte, fn func(key string, value json.RawMessage) error) error {
+	d := json.NewDecoder(bytes.NewReader(p))
+	if t, err := d.Token(); err != nil {
+		return err
+	} else if t != json.Delim('{') {
+		return fmt.Errorf("unexpected %v, expecting an object", t)
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := t.(string)
+		var value json.RawMessage
+		if err := d.Decode(&value); err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendField appends a field in the native protocol format: KEY=value\n, or
+// KEY\n followed by the value size as a 64 bits little endian integer, the
+// value and \n if the value contains a newline.
+func appendField(dst []byte, key, value string) []byte {
+	dst = append(dst, key...)
+	if strings.IndexByte(value, '\n') < 0 {
+		dst = append(dst, '=')
+		dst = append(dst, value...)
+		return append(dst, '\n')
+	}
+	dst = append(dst, '\n')
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(len(value)))
+	dst = append(dst, value...)
+	return append(dst, '\n')
+}
+
+// send sends an entry to journald, falling back to a memfd if it is too
+// large for a datagram.
+func (w *Writer) send(entry []byte) error {
+	conn, err := w.socket()
+	if err != nil {
+		return err
+	}
+	addr := &net.UnixAddr{Name: w.SocketPath, Net: "unixgram"}
+	_, _, err = conn.WriteMsgUnix(entry, nil, addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		err = sendMemfd(conn, addr, entry)
+	}
+	return err
+}
+
+// socket returns the unbound datagram socket used to send entries, creating
+// it if needed.
+func (w *Writer) socket() (*net.UnixConn, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		return w.conn, nil
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: "", Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return conn, nil
+}
+
+// sendMemfd writes entry to a sealed memfd and passes its file descriptor to
+// journald.
+func sendMemfd(conn *net.UnixConn, addr *net.UnixAddr, entry []byte) error {
+	fd, err := unix.MemfdCreate("journald-entry", unix.MFD_CLOEXEC|unix.MFD_ALLOW_SEALING)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	for b := entry; len(b) > 0; {
+		n, err := unix.Write(fd, b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	const seals = unix.F_SEAL_SEAL | unix.F_SEAL_SHRINK | unix.F_SEAL_GROW | unix.F_SEAL_WRITE
+	if _, err := unix.FcntlInt(uintptr(fd), unix.F_ADD_SEALS, seals); err != nil {
+		return err
+	}
+	_, _, err = conn.WriteMsgUnix(nil, unix.UnixRights(fd), addr)
+	return err
+}
+
+// Close closes the journald socket.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func putEntry(e *entry) {
+	// Proper usage of a sync.Pool requires each entry to have approximately
+	// the same memory cost. To obtain this property when the stored type
+	// contains a variably-sized buffer, we add a hard limit on the maximum buffer
+	// to place back in the pool.
+	//
+	// See https://golang.org/issue/23199
+	const maxSize = 1 << 16 // 64KiB
+	if cap(e.buf) <= maxSize {
+		entryPool.Put(e)
+	}
+}
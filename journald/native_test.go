@@ -0,0 +1,201 @@
+//go:build linux
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/journal"
+	"github.com/rs/zerolog"
+	"golang.org/x/sys/unix"
+)
+
+// listenJournal starts a unixgram listener standing in for journald and
+// returns a Writer sending to it.
+func listenJournal(t *testing.T) (*Writer, *net.UnixConn) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "socket")
+	l, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	w := NewWriter(func(w *Writer) {
+		w.SocketPath = path
+	})
+	t.Cleanup(func() { w.Close() })
+	return w, l
+}
+
+// readEntry reads an entry from l, following memfd file descriptors, and
+// parses its fields.
+func readEntry(t *testing.T, l *net.UnixConn) map[string]string {
+	t.Helper()
+	buf := make([]byte, 1<<16)
+	oob := make([]byte, 64)
+	n, oobn, _, _, err := l.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf[:n]
+	if oobn > 0 {
+		msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := unix.ParseUnixRights(&msgs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "memfd")
+		defer f.Close()
+		// The file offset is shared with the writer, rewind it.
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if _, err := b.ReadFrom(f); err != nil {
+			t.Fatal(err)
+		}
+		data = b.Bytes()
+	}
+	return parseEntry(t, data)
+}
+
+func parseEntry(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexAny(data, "=\n")
+		if i < 0 {
+			t.Fatalf("Invalid entry: %q", data)
+		}
+		key := string(data[:i])
+		if data[i] == '=' {
+			data = data[i+1:]
+			j := bytes.IndexByte(data, '\n')
+			fields[key] = string(data[:j])
+			data = data[j+1:]
+			continue
+		}
+		data = data[i+1:]
+		size := binary.LittleEndian.Uint64(data)
+		data = data[8:]
+		fields[key] = string(data[:size])
+		data = data[size+1:]
+	}
+	return fields
+}
+
+func TestWriter(t *testing.T) {
+	w, l := listenJournal(t)
+	log := zerolog.New(w)
+	log.Warn().Str("test-key", "multi\nline").Int("number", 42).Dict("obj", zerolog.Dict().Bool("b", true)).Msg("Test message")
+
+	got := readEntry(t, l)
+	want := map[string]string{
+		"MESSAGE":  "Test message",
+		"PRIORITY": "4",
+		"TEST_KEY": "multi\nline",
+		"NUMBER":   "42",
+		"OBJ":      `{"b":true}`,
+		"JSON":     `{"level":"warn","test-key":"multi\nline","number":42,"obj":{"b":true},"message":"Test message"}` + "\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalid entry:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestWriterPriority(t *testing.T) {
+	w, l := listenJournal(t)
+	if _, err := w.Write([]byte(`{"level":"error","message":"a"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEntry(t, l)["PRIORITY"]; got != "3" {
+		t.Errorf("Invalid priority from level field: %s", got)
+	}
+	if _, err := w.Write([]byte(`{"message":"a"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEntry(t, l)["PRIORITY"]; got != "5" {
+		t.Errorf("Invalid default priority: %s", got)
+	}
+}
+
+func TestWriterOptions(t *testing.T) {
+	w, l := listenJournal(t)
+	const NoticeLevel = zerolog.Level(10)
+	w.FlattenFields = true
+	w.Priorities = map[zerolog.Level]journal.Priority{
+		NoticeLevel:        journal.PriNotice,
+		zerolog.FatalLevel: journal.PriEmerg,
+	}
+	w.SyslogIdentifier = "myapp"
+	w.CodeLocation = true
+
+	log := zerolog.New(w)
+	log.WithLevel(NoticeLevel).
+		Str("caller", "/src/main.go:42").
+		Dict("http", zerolog.Dict().Int("status", 200).Dict("req", zerolog.Dict().Str("method", "GET"))).
+		Msg("m")
+	got := readEntry(t, l)
+	delete(got, "JSON")
+	want := map[string]string{
+		"MESSAGE":           "m",
+		"PRIORITY":          "5",
+		"CODE_FILE":         "/src/main.go",
+		"CODE_LINE":         "42",
+		"HTTP_STATUS":       "200",
+		"HTTP_REQ_METHOD":   "GET",
+		"SYSLOG_IDENTIFIER": "myapp",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalid entry:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	if _, err := w.Write([]byte(`{"level":"fatal"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEntry(t, l)["PRIORITY"]; got != "0" {
+		t.Errorf("Invalid overridden priority: %s", got)
+	}
+	if _, err := w.Write([]byte(`{"level":"error"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEntry(t, l)["PRIORITY"]; got != "3" {
+		t.Errorf("Invalid default priority: %s", got)
+	}
+}
+
+func TestWriterMemfd(t *testing.T) {
+	w, l := listenJournal(t)
+	big := strings.Repeat("x", 1<<20)
+	n, err := w.Write([]byte(`{"big":"` + big + `"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(big)+10 {
+		t.Errorf("Invalid written bytes: %d", n)
+	}
+	if got := readEntry(t, l)["BIG"]; got != big {
+		t.Errorf("Invalid field size: %d", len(got))
+	}
+}
+
+func TestWriterNoSocket(t *testing.T) {
+	w := NewWriter(func(w *Writer) {
+		w.SocketPath = filepath.Join(t.TempDir(), "missing")
+	})
+	if _, err := w.Write([]byte(`{"message":"a"}`)); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,164 @@
+// Package levelctl changes zerolog levels at run time, over HTTP and with
+// signals, so debug logs can be enabled in production without a restart.
+//
+// The global level (see zerolog.SetGlobalLevel), the levels of the loggers
+// created with zerolog.Logger.Named (see zerolog.SetNamedLevel) and levels
+// read by the loggers created with zerolog.Logger.LevelVar can be controlled:
+//
+//	dbLog := log.Named("db")
+//	cacheLevel := zerolog.NewLevelVar(zerolog.InfoLevel)
+//	cacheLog := log.Logger.LevelVar(cacheLevel)
+//
+//	http.Handle("/debug/level", levelctl.NewHandler(func(h *levelctl.Handler) {
+//		h.Levels = map[string]*zerolog.LevelVar{"cache": cacheLevel}
+//	}))
+//	levelctl.HandleSignals(nil)
+package levelctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+// levels is the JSON representation of the levels used by Handler.
+type levels struct {
+	Level   string            `json:"level,omitempty"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// Handler is an http.Handler reading and changing the global level and named
+// levels.
+//
+// GET returns the levels as a JSON object:
+//
+//	{"level":"info","loggers":{"db":"debug","db.pool":"trace"}}
+//
+// PUT changes the levels present in a JSON object of the same form and
+// returns the new levels. Omitted levels are left unchanged. Loggers which are
+// not in Levels are name prefixes of the loggers created with Named (see
+// zerolog.SetNamedLevel); an empty level removes the level of a prefix.
+//
+// The handler must not be exposed publicly.
+type Handler struct {
+	// Levels are the named levels exposed in addition to the global level and
+	// the levels of the named loggers.
+	Levels map[string]*zerolog.LevelVar
+}
+
+// NewHandler creates a Handler.
+func NewHandler(options ...func(h *Handler)) *Handler {
+	h := &Handler{}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut:
+		var req levels
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := h.set(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := levels{
+		Level: zerolog.GlobalLevel().String(),
+	}
+	named := zerolog.NamedLevels()
+	if len(h.Levels)+len(named) > 0 {
+		resp.Loggers = make(map[string]string, len(h.Levels)+len(named))
+		for prefix, l := range named {
+			resp.Loggers[prefix] = l.String()
+		}
+		for name, v := range h.Levels {
+			resp.Loggers[name] = v.String()
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// set validates all the levels of req before changing any of them.
+func (h *Handler) set(req levels) error {
+	var global zerolog.Level
+	if req.Level != "" {
+		var err error
+		if global, err = parseLevel(req.Level); err != nil {
+			return err
+		}
+	}
+	vars := make(map[*zerolog.LevelVar]zerolog.Level, len(req.Loggers))
+	prefixes := make(map[string]zerolog.Level, len(req.Loggers))
+	for name, s := range req.Loggers {
+		v, ok := h.Levels[name]
+		if !ok && s == "" {
+			prefixes[name] = zerolog.NoLevel
+			continue
+		}
+		l, err := parseLevel(s)
+		if err != nil {
+			return err
+		}
+		if ok {
+			vars[v] = l
+		} else {
+			prefixes[name] = l
+		}
+	}
+
+	if req.Level != "" {
+		zerolog.SetGlobalLevel(global)
+	}
+	for v, l := range vars {
+		v.Set(l)
+	}
+	for prefix, l := range prefixes {
+		zerolog.SetNamedLevel(prefix, l)
+	}
+	return nil
+}
+
+// parseLevel parses a level name, rejecting the absent level.
+func parseLevel(s string) (zerolog.Level, error) {
+	l, err := zerolog.ParseLevel(s)
+	if err != nil || l == zerolog.NoLevel {
+		return l, fmt.Errorf("invalid level: %q", s)
+	}
+	return l, nil
+}
+
+// shiftLevel adds delta to the level of v, or to the global level if v is
+// nil, keeping it between zerolog.TraceLevel and zerolog.PanicLevel.
+func shiftLevel(v *zerolog.LevelVar, delta int) {
+	l := zerolog.GlobalLevel()
+	if v != nil {
+		l = v.Level()
+	}
+	l += zerolog.Level(delta)
+	if l < zerolog.TraceLevel {
+		l = zerolog.TraceLevel
+	} else if l > zerolog.PanicLevel {
+		l = zerolog.PanicLevel
+	}
+	if v != nil {
+		v.Set(l)
+	} else {
+		zerolog.SetGlobalLevel(l)
+	}
+}
@@ -0,0 +1,63 @@
+package levelctl
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHandler(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	defer zerolog.ParseNamedLevels("")
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	db := zerolog.NewLevelVar(zerolog.WarnLevel)
+	h := NewHandler(func(h *Handler) {
+		h.Levels = map[string]*zerolog.LevelVar{"db": db}
+	})
+
+	tests := []struct {
+		method, body string
+		code         int
+		resp         string
+	}{
+		{http.MethodGet, "", http.StatusOK, `{"level":"info","loggers":{"db":"warn"}}`},
+		{http.MethodPut, `{"level":"debug"}`, http.StatusOK, `{"level":"debug","loggers":{"db":"warn"}}`},
+		{http.MethodPut, `{"loggers":{"db":"TRACE"}}`, http.StatusOK, `{"level":"debug","loggers":{"db":"trace"}}`},
+		{http.MethodPut, `{"level":"error","loggers":{"http":"verbose"}}`, http.StatusBadRequest, `invalid level: "verbose"`},
+		{http.MethodPut, `{"loggers":{"http":"warn"}}`, http.StatusOK, `{"level":"debug","loggers":{"db":"trace","http":"warn"}}`},
+		{http.MethodPut, `{"loggers":{"http":""}}`, http.StatusOK, `{"level":"debug","loggers":{"db":"trace"}}`},
+		{http.MethodPut, `{"loggers":{"db":""}}`, http.StatusBadRequest, `invalid level: ""`},
+		{http.MethodPut, `{"level":"verbose"}`, http.StatusBadRequest, `invalid level: "verbose"`},
+		{http.MethodPut, `{`, http.StatusBadRequest, "invalid request: unexpected EOF"},
+		{http.MethodPost, "", http.StatusMethodNotAllowed, "Method Not Allowed"},
+		{http.MethodGet, "", http.StatusOK, `{"level":"debug","loggers":{"db":"trace"}}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body)))
+		if w.Code != tt.code || strings.TrimSpace(w.Body.String()) != tt.resp {
+			t.Errorf("%s %s: got %d %s, want %d %s", tt.method, tt.body, w.Code, w.Body.String(), tt.code, tt.resp)
+		}
+	}
+}
+
+func TestShiftLevel(t *testing.T) {
+	v := zerolog.NewLevelVar(zerolog.InfoLevel)
+	shiftLevel(v, -1)
+	if v.Level() != zerolog.DebugLevel {
+		t.Errorf("expected debug, got %v", v)
+	}
+	shiftLevel(v, -1)
+	shiftLevel(v, -1)
+	if v.Level() != zerolog.TraceLevel {
+		t.Errorf("expected trace, got %v", v)
+	}
+	v.Set(zerolog.PanicLevel)
+	shiftLevel(v, 1)
+	if v.Level() != zerolog.PanicLevel {
+		t.Errorf("expected panic, got %v", v)
+	}
+}
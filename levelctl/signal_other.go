@@ -0,0 +1,17 @@
+//go:build !unix
+
+package levelctl
+
+import (
+	"github.com/rs/zerolog"
+)
+
+// HandleSignals lowers the level of v by one, to log more, on SIGUSR1, and
+// raises it by one, to log less, on SIGUSR2, between zerolog.TraceLevel and
+// zerolog.PanicLevel. If v is nil, the global level is changed. It returns a
+// function to stop handling the signals.
+//
+// HandleSignals does nothing on systems without SIGUSR1 and SIGUSR2.
+func HandleSignals(v *zerolog.LevelVar) (stop func()) {
+	return func() {}
+}
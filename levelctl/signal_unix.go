@@ -0,0 +1,41 @@
+//go:build unix
+
+package levelctl
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+)
+
+// HandleSignals lowers the level of v by one, to log more, on SIGUSR1, and
+// raises it by one, to log less, on SIGUSR2, between zerolog.TraceLevel and
+// zerolog.PanicLevel. If v is nil, the global level is changed. It returns a
+// function to stop handling the signals.
+//
+// HandleSignals does nothing on systems without SIGUSR1 and SIGUSR2.
+func HandleSignals(v *zerolog.LevelVar) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for {
+			select {
+			case sig := <-c:
+				delta := 1
+				if sig == syscall.SIGUSR1 {
+					delta = -1
+				}
+				shiftLevel(v, delta)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
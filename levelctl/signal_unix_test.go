@@ -0,0 +1,37 @@
+//go:build unix
+
+package levelctl
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHandleSignals(t *testing.T) {
+	v := zerolog.NewLevelVar(zerolog.InfoLevel)
+	stop := HandleSignals(v)
+	defer stop()
+
+	for _, tt := range []struct {
+		sig  syscall.Signal
+		want zerolog.Level
+	}{
+		{syscall.SIGUSR1, zerolog.DebugLevel},
+		{syscall.SIGUSR2, zerolog.InfoLevel},
+		{syscall.SIGUSR2, zerolog.WarnLevel},
+	} {
+		if err := syscall.Kill(syscall.Getpid(), tt.sig); err != nil {
+			t.Fatal(err)
+		}
+		deadline := time.Now().Add(time.Second)
+		for v.Level() != tt.want && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if v.Level() != tt.want {
+			t.Errorf("after %v: expected %v, got %v", tt.sig, tt.want, v)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package zerolog
+
+import (
+	"sync/atomic"
+)
+
+// LevelVar is a Level variable, to allow a Logger level to change
+// dynamically. It is safe for use by multiple goroutines. The zero LevelVar
+// corresponds to DebugLevel.
+//
+// Use Logger.LevelVar to make a logger read its level from a LevelVar.
+type LevelVar struct {
+	val atomic.Int32
+}
+
+// NewLevelVar creates a LevelVar set to l.
+func NewLevelVar(l Level) *LevelVar {
+	v := &LevelVar{}
+	v.Set(l)
+	return v
+}
+
+// Level returns v's level.
+func (v *LevelVar) Level() Level {
+	return Level(v.val.Load())
+}
+
+// Set sets v's level to l.
+func (v *LevelVar) Set(l Level) {
+	v.val.Store(int32(l))
+}
+
+// String returns the name of v's level.
+func (v *LevelVar) String() string {
+	return v.Level().String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v *LevelVar) MarshalText() ([]byte, error) {
+	return v.Level().MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *LevelVar) UnmarshalText(text []byte) error {
+	var l Level
+	if err := l.UnmarshalText(text); err != nil {
+		return err
+	}
+	v.Set(l)
+	return nil
+}
+
+// LevelVar creates a child logger with the minimum accepted level read from v
+// on each event, so it can be changed at run time. While v is set to NoLevel,
+// the level set with Level is used. A later call to Level replaces v. It
+// overrides the level set for the name of the logger (see Named).
+func (l Logger) LevelVar(v *LevelVar) Logger {
+	l.levelVar = v
+	l.named = nil
+	return l
+}
@@ -0,0 +1,57 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestLevelVar(t *testing.T) {
+	out := &bytes.Buffer{}
+	v := NewLevelVar(WarnLevel)
+	log := New(out).LevelVar(v)
+	sub := log.With().Str("sub", "1").Logger()
+
+	sub.Info().Msg("filtered")
+	if out.Len() != 0 {
+		t.Errorf("expected info to be filtered, got %s", decodeIfBinaryToString(out.Bytes()))
+	}
+	v.Set(DebugLevel)
+	if sub.GetLevel() != DebugLevel {
+		t.Errorf("invalid level: %v", sub.GetLevel())
+	}
+	sub.Debug().Msg("logged")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"debug","sub":"1","message":"logged"}`+"\n"; got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	v.Set(Disabled)
+	sub.Error().Msg("disabled")
+	if out.Len() != 0 {
+		t.Errorf("expected disabled logger, got %s", decodeIfBinaryToString(out.Bytes()))
+	}
+
+	// Level replaces the LevelVar.
+	sub = sub.Level(InfoLevel)
+	sub.Info().Msg("logged")
+	if out.Len() == 0 {
+		t.Error("expected Level to replace the LevelVar")
+	}
+}
+
+func TestLevelVarText(t *testing.T) {
+	var v LevelVar
+	if err := json.Unmarshal([]byte(`"error"`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Level() != ErrorLevel {
+		t.Errorf("invalid level: %v", v.Level())
+	}
+	if p, _ := json.Marshal(&v); string(p) != `"error"` {
+		t.Errorf("invalid JSON: %s", p)
+	}
+	if err := v.UnmarshalText([]byte("verbose")); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -227,13 +227,17 @@ func (l Level) MarshalText() ([]byte, error) {
 // serialization to the Writer. If your Writer is not thread safe,
 // you may consider a sync wrapper.
 type Logger struct {
-	w       LevelWriter
-	level   Level
-	sampler Sampler
-	context []byte
-	hooks   []Hook
-	stack   bool
-	ctx     context.Context
+	w        LevelWriter
+	level    Level
+	levelVar *LevelVar
+	name     string
+	named    *namedLevelCache
+	sampler  Sampler
+	context  []byte
+	hooks    []Hook
+	stack    bool
+	ctx      context.Context
+	schema   *Schema
 }
 
 // New creates a root logger with given output writer. If the output writer implements
@@ -263,8 +267,12 @@ func Nop() Logger {
 func (l Logger) Output(w io.Writer) Logger {
 	l2 := New(w)
 	l2.level = l.level
+	l2.levelVar = l.levelVar
+	l2.name = l.name
+	l2.named = l.named
 	l2.sampler = l.sampler
 	l2.stack = l.stack
+	l2.schema = l.schema
 	if len(l.hooks) > 0 {
 		l2.hooks = append(l2.hooks, l.hooks...)
 	}
@@ -308,13 +316,42 @@ func (l *Logger) UpdateContext(update func(c Context) Context) {
 }
 
 // Level creates a child logger with the minimum accepted level set to level.
+// It overrides the level read from a LevelVar or set for the name of the
+// logger (see Named).
 func (l Logger) Level(lvl Level) Logger {
 	l.level = lvl
+	l.levelVar = nil
+	l.named = nil
 	return l
 }
 
 // GetLevel returns the current Level of l.
 func (l Logger) GetLevel() Level {
+	return l.currentLevel()
+}
+
+// currentLevel is GetLevel without copying l. The common case of a logger
+// without named level or LevelVar is kept small enough to be inlined.
+func (l *Logger) currentLevel() Level {
+	if l.named == nil && l.levelVar == nil {
+		return l.level
+	}
+	return l.dynamicLevel()
+}
+
+// dynamicLevel returns the level set for the name of l or read from its
+// LevelVar, falling back to l.level.
+func (l *Logger) dynamicLevel() Level {
+	if l.named != nil {
+		if lvl := l.named.level(l.name); lvl != NoLevel {
+			return lvl
+		}
+	}
+	if l.levelVar != nil {
+		if lvl := l.levelVar.Level(); lvl != NoLevel {
+			return lvl
+		}
+	}
 	return l.level
 }
 
@@ -385,16 +422,18 @@ func (l *Logger) Err(err error) *Event {
 // Fatal starts a new message with fatal level. The FatalExitFunc interceptor function
 // is called by the Msg method, which by default terminates the program immediately
 // using os.Exit(1), any desired behavior can be implemented by setting FatalExitFunc.
+// The writer of the logger, if it implements Flusher, and the writers registered
+// with RegisterFlusher are flushed, and the writer of the logger is closed,
+// before, for at most FlushTimeout.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l *Logger) Fatal() *Event {
 	return l.newEvent(FatalLevel, func(msg string) {
-		if closer, ok := l.w.(io.Closer); ok {
-			// Close the writer to flush any buffered message. Otherwise the message
-			// could be lost if FatalExitFunc() terminates the program immediately or
-			// os.Exit(1) is called if not FatalExitFunc isn't set (default).
-			closer.Close()
-		}
+		// Flush and close the writer to write any buffered message. Otherwise the
+		// message could be lost if FatalExitFunc() terminates the program
+		// immediately or os.Exit(1) is called if not FatalExitFunc isn't set
+		// (default).
+		flushBeforeExit(l.w, true)
 		if FatalExitFunc != nil {
 			FatalExitFunc()
 		} else {
@@ -405,10 +444,14 @@ func (l *Logger) Fatal() *Event {
 
 // Panic starts a new message with panic level. The panic() function
 // is called by the Msg method, which stops the ordinary flow of a goroutine.
+// Buffering writers are flushed before, as with Fatal.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l *Logger) Panic() *Event {
-	return l.newEvent(PanicLevel, func(msg string) { panic(msg) })
+	return l.newEvent(PanicLevel, func(msg string) {
+		flushBeforeExit(l.w, false)
+		panic(msg)
+	})
 }
 
 // WithLevel starts a new message with level. Unlike Fatal and Panic
@@ -486,6 +529,12 @@ func (l Logger) Write(p []byte) (n int, err error) {
 }
 
 func (l *Logger) newEvent(level Level, done func(string)) *Event {
+	return l.newEventLevelField(level, done, true)
+}
+
+// newEventLevelField is like newEvent but only adds the level field if
+// levelField is true.
+func (l *Logger) newEventLevelField(level Level, done func(string), levelField bool) *Event {
 	enabled := l.should(level)
 	if !enabled {
 		if done != nil {
@@ -495,8 +544,12 @@ func (l *Logger) newEvent(level Level, done func(string)) *Event {
 	}
 	e := newEvent(l.w, level, l.stack, l.ctx, l.hooks)
 	e.done = done
-	if level != NoLevel && LevelFieldName != "" {
-		e.Str(LevelFieldName, LevelFieldMarshalFunc(level))
+	e.schema = l.schema
+	if name := l.schema.levelFieldName(); levelField && level != NoLevel && name != "" {
+		e.Str(name, l.schema.levelFieldMarshalFunc()(level))
+	}
+	if l.name != "" && LoggerNameFieldName != "" {
+		e.Str(LoggerNameFieldName, l.name)
 	}
 	if len(l.context) > 1 {
 		e.buf = enc.AppendObjectData(e.buf, l.context)
@@ -505,20 +558,22 @@ func (l *Logger) newEvent(level Level, done func(string)) *Event {
 }
 
 func (l *Logger) scratchEvent() *Event {
-	return newEvent(LevelWriterAdapter{io.Discard}, DebugLevel, l.stack, l.ctx, l.hooks)
+	e := newEvent(LevelWriterAdapter{io.Discard}, DebugLevel, l.stack, l.ctx, l.hooks)
+	e.schema = l.schema
+	return e
 }
 
 // disabled returns true if the logger is a disabled or nop logger.
 func (l *Logger) disabled() bool {
-	return l.w == nil || l.level == Disabled
+	return l.w == nil || l.currentLevel() == Disabled
 }
 
 // should returns true if the log event should be logged.
 func (l *Logger) should(lvl Level) bool {
-	if l.disabled() {
+	if l.w == nil {
 		return false
 	}
-	if lvl < l.level || lvl < GlobalLevel() {
+	if level := l.currentLevel(); level == Disabled || lvl < level || lvl < GlobalLevel() {
 		return false
 	}
 	if l.sampler != nil && !samplingDisabled() {
@@ -28,6 +28,11 @@ func Level(level zerolog.Level) zerolog.Logger {
 	return Logger.Level(level)
 }
 
+// Named creates a child logger named name. See zerolog.Logger.Named.
+func Named(name string) zerolog.Logger {
+	return Logger.Named(name)
+}
+
 // Sample returns a logger with the s sampler.
 func Sample(s zerolog.Sampler) zerolog.Logger {
 	return Logger.Sample(s)
@@ -0,0 +1,139 @@
+package zerolog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// namedLevels holds the levels set for the named loggers. gen is incremented
+// on each change so the named loggers resolve their level again.
+var namedLevels = struct {
+	sync.RWMutex
+	levels map[string]Level // by name prefix
+	gen    atomic.Uint64
+}{
+	levels: map[string]Level{},
+}
+
+// namedLevelCache caches the level resolved for the name of a logger, along
+// with the generation of namedLevels it was resolved from.
+type namedLevelCache struct {
+	v atomic.Uint64 // (generation+1)<<8 | level
+}
+
+// level returns the level set for name, or NoLevel.
+func (c *namedLevelCache) level(name string) Level {
+	v := c.v.Load()
+	if v>>8 == namedLevels.gen.Load()+1 {
+		return Level(int8(v))
+	}
+	namedLevels.RLock()
+	l := namedLevel(name)
+	gen := namedLevels.gen.Load()
+	namedLevels.RUnlock()
+	c.v.Store((gen+1)<<8 | uint64(uint8(l)))
+	return l
+}
+
+// Named creates a child logger named name, or parent.name if l is already
+// named, with the name added to each event as the LoggerNameFieldName field.
+//
+// The level of a named logger can be changed at run time with SetNamedLevel
+// and ParseNamedLevels, per name prefix: the level set for "db" applies to the
+// "db" and "db.pool" loggers, unless a level is set for "db.pool". While no
+// level is set for its name, the logger uses its own level. Calling Level or
+// LevelVar on the named logger overrides the level set for its name.
+//
+// Levels are resolved lazily and no state is kept per name, so names may be
+// created per request or per tenant.
+func (l Logger) Named(name string) Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	l.name = name
+	l.named = &namedLevelCache{}
+	return l
+}
+
+// GetName returns the name of l, set with Named.
+func (l Logger) GetName() string {
+	return l.name
+}
+
+// namedLevel returns the level of the most specific prefix of name, or
+// NoLevel. namedLevels must be read locked.
+func namedLevel(name string) Level {
+	for {
+		if l, ok := namedLevels.levels[name]; ok {
+			return l
+		}
+		if name == "" {
+			return NoLevel
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			i = 0
+		}
+		name = name[:i]
+	}
+}
+
+// SetNamedLevel sets the level of the loggers created with Named whose name is
+// prefix or starts with prefix followed by a dot. The empty prefix matches all
+// the named loggers. Setting NoLevel removes the level set for prefix.
+func SetNamedLevel(prefix string, l Level) {
+	namedLevels.Lock()
+	defer namedLevels.Unlock()
+	if l == NoLevel {
+		delete(namedLevels.levels, prefix)
+	} else {
+		namedLevels.levels[prefix] = l
+	}
+	namedLevels.gen.Add(1)
+}
+
+// NamedLevels returns the levels set with SetNamedLevel, by name prefix.
+func NamedLevels() map[string]Level {
+	namedLevels.RLock()
+	defer namedLevels.RUnlock()
+	levels := make(map[string]Level, len(namedLevels.levels))
+	for prefix, l := range namedLevels.levels {
+		levels[prefix] = l
+	}
+	return levels
+}
+
+// ParseNamedLevels replaces the levels of the named loggers by the ones of a
+// comma separated list of prefix=level pairs, like "db=debug,http=warn". A
+// level without prefix applies to all the named loggers. It is typically read
+// from an environment variable:
+//
+//	if err := zerolog.ParseNamedLevels(os.Getenv("ZEROLOG_LEVELS")); err != nil {
+//		log.Fatal().Err(err).Msg("invalid ZEROLOG_LEVELS")
+//	}
+func ParseNamedLevels(spec string) error {
+	levels := map[string]Level{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		prefix, s, found := strings.Cut(item, "=")
+		if !found {
+			prefix, s = "", item
+		}
+		l, err := ParseLevel(strings.TrimSpace(s))
+		if err != nil || l == NoLevel {
+			return fmt.Errorf("invalid level in %q", item)
+		}
+		levels[strings.TrimSpace(prefix)] = l
+	}
+
+	namedLevels.Lock()
+	defer namedLevels.Unlock()
+	namedLevels.levels = levels
+	namedLevels.gen.Add(1)
+	return nil
+}
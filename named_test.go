@@ -0,0 +1,73 @@
+package zerolog
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestNamed(t *testing.T) {
+	defer ParseNamedLevels("")
+
+	out := &bytes.Buffer{}
+	db := New(out).Level(InfoLevel).Named("db")
+	pool := db.Named("pool")
+	if pool.GetName() != "db.pool" {
+		t.Errorf("invalid name: %s", pool.GetName())
+	}
+
+	pool.Info().Msg("a")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","logger":"db.pool","message":"a"}`+"\n"; got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	tests := []struct {
+		spec      string
+		db, pool  Level
+		otherName Level
+	}{
+		{"", InfoLevel, InfoLevel, InfoLevel},
+		{"db=debug", DebugLevel, DebugLevel, InfoLevel},
+		{"db=debug, db.pool=trace", DebugLevel, TraceLevel, InfoLevel},
+		{"warn,db.pool=error", WarnLevel, ErrorLevel, WarnLevel},
+		{"dbx=error", InfoLevel, InfoLevel, InfoLevel},
+	}
+	other := New(out).Level(InfoLevel).Named("http")
+	for _, tt := range tests {
+		if err := ParseNamedLevels(tt.spec); err != nil {
+			t.Fatalf("ParseNamedLevels(%q): %v", tt.spec, err)
+		}
+		if db.GetLevel() != tt.db || pool.GetLevel() != tt.pool || other.GetLevel() != tt.otherName {
+			t.Errorf("%q: invalid levels db=%v, db.pool=%v, http=%v", tt.spec, db.GetLevel(), pool.GetLevel(), other.GetLevel())
+		}
+	}
+
+	// Loggers created after the levels are set use them too.
+	SetNamedLevel("cache", ErrorLevel)
+	if l := New(out).Named("cache").Named("lru"); l.GetLevel() != ErrorLevel {
+		t.Errorf("invalid level: %v", l.GetLevel())
+	}
+	if got, want := NamedLevels(), map[string]Level{"dbx": ErrorLevel, "cache": ErrorLevel}; !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid named levels: %v", got)
+	}
+	SetNamedLevel("cache", NoLevel)
+	if _, ok := NamedLevels()["cache"]; ok {
+		t.Error("expected NoLevel to remove the level")
+	}
+
+	// Level and LevelVar override the level set for the name.
+	SetNamedLevel("db", DebugLevel)
+	if l := db.Level(ErrorLevel); l.GetLevel() != ErrorLevel || l.GetName() != "db" {
+		t.Errorf("invalid level: %v", l.GetLevel())
+	}
+	if l := db.LevelVar(NewLevelVar(WarnLevel)); l.GetLevel() != WarnLevel {
+		t.Errorf("invalid level: %v", l.GetLevel())
+	}
+	if l := db.Level(ErrorLevel).Named("pool"); l.GetLevel() != DebugLevel {
+		t.Errorf("invalid level: %v", l.GetLevel())
+	}
+
+	if err := ParseNamedLevels("db=verbose"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,346 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Framing defines how NetWriter delimits events on the wire.
+type Framing int
+
+const (
+	// FramingNewline terminates each event with a newline, unless it already
+	// ends with one. This is the format expected by most log shippers.
+	FramingNewline Framing = iota
+	// FramingOctetCounting prefixes each event, stripped of its trailing
+	// newline, with its length in decimal followed by a space, as defined
+	// by RFC 6587.
+	FramingOctetCounting
+	// FramingLengthPrefix prefixes each event, stripped of its trailing
+	// newline, with its length as a 4 bytes big endian integer.
+	FramingLengthPrefix
+	// FramingNone writes events as is. It is usually used with datagram
+	// networks where each event is sent in its own packet.
+	FramingNone
+	// FramingNullByte terminates each event, stripped of its trailing
+	// newline, with a null byte, as expected by GELF over TCP.
+	FramingNullByte
+)
+
+// ErrNetWriterBufferFull is returned by NetWriter when an event can't be sent
+// and there is no room left in the retry buffer.
+var ErrNetWriterBufferFull = errors.New("zerolog: NetWriter retry buffer full")
+
+// NetWriter is a writer sending events to a network socket (TCP, UDP, Unix
+// sockets, optionally over TLS), for instance to a log shipper like Fluent Bit
+// or Vector.
+//
+// The connection is established on the first write and re-established on
+// failure, after an exponential backoff delay. Events which can't be sent
+// are kept in a retry buffer, up to BufferSize bytes, and sent in order once
+// the connection is back. When the buffer is full, the oldest events are
+// dropped.
+//
+// NetWriter is safe for concurrent use but writes are synchronous: wrap it
+// in an AsyncWriter or a diode.Writer to avoid blocking log producers.
+type NetWriter struct {
+	// Network is the network name as accepted by net.Dial: "tcp", "udp",
+	// "unix", "unixgram" etc.
+	Network string
+
+	// Address is the address to connect to.
+	Address string
+
+	// TLSConfig, if not nil, is used to establish a TLS connection.
+	TLSConfig *tls.Config
+
+	// Framing defines how events are delimited. Default is FramingNewline.
+	Framing Framing
+
+	// DialTimeout is the maximum time to wait for the connection to be
+	// established. Default, if 0, is 5 seconds.
+	DialTimeout time.Duration
+
+	// WriteTimeout, if not 0, is the maximum time to wait for a write to
+	// complete.
+	WriteTimeout time.Duration
+
+	// MinBackoff is the delay before reconnecting after a first failure. The
+	// delay doubles on each consecutive failure up to MaxBackoff. Defaults,
+	// if 0, are 100 milliseconds and 30 seconds.
+	MinBackoff, MaxBackoff time.Duration
+
+	// BufferSize is the maximum number of bytes of events kept while the
+	// connection is down. Default, if 0, is 1MiB. If negative, events are not
+	// buffered and write errors are returned.
+	BufferSize int
+
+	// Dial, if not nil, is used instead of net.Dialer to establish the
+	// connection.
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+
+	mu       sync.Mutex
+	conn     net.Conn
+	pending  [][]byte // framed events waiting to be sent
+	buffered int      // size of pending in bytes
+	backoff  time.Duration
+	nextDial time.Time
+	dropped  atomic.Uint64
+}
+
+// Defaults of the NetWriter settings left to 0.
+const (
+	netWriterDialTimeout = 5 * time.Second
+	netWriterMinBackoff  = 100 * time.Millisecond
+	netWriterMaxBackoff  = 30 * time.Second
+	netWriterBufferSize  = 1 << 20
+)
+
+// NewNetWriter creates a NetWriter sending events to address on network. No
+// connection is established until the first write.
+func NewNetWriter(network, address string, options ...func(w *NetWriter)) *NetWriter {
+	w := &NetWriter{
+		Network:     network,
+		Address:     address,
+		DialTimeout: netWriterDialTimeout,
+		MinBackoff:  netWriterMinBackoff,
+		MaxBackoff:  netWriterMaxBackoff,
+		BufferSize:  netWriterBufferSize,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements the io.Writer interface. It returns an error only if the
+// event could neither be sent nor buffered.
+func (w *NetWriter) Write(p []byte) (n int, err error) {
+	frame := w.frame(p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err = w.send(frame); err != nil {
+		if w.BufferSize < 0 {
+			return 0, err
+		}
+		if !w.buffer(frame) {
+			return 0, ErrNetWriterBufferFull
+		}
+	}
+	return len(p), nil
+}
+
+// frame returns a copy of p framed according to w.Framing.
+func (w *NetWriter) frame(p []byte) []byte {
+	switch w.Framing {
+	case FramingOctetCounting:
+		p = bytes.TrimSuffix(p, []byte{'\n'})
+		frame := make([]byte, 0, len(p)+11)
+		frame = strconv.AppendInt(frame, int64(len(p)), 10)
+		frame = append(frame, ' ')
+		return append(frame, p...)
+	case FramingLengthPrefix:
+		p = bytes.TrimSuffix(p, []byte{'\n'})
+		frame := make([]byte, 4, len(p)+4)
+		binary.BigEndian.PutUint32(frame, uint32(len(p)))
+		return append(frame, p...)
+	case FramingNone:
+		return append([]byte(nil), p...)
+	case FramingNullByte:
+		p = bytes.TrimSuffix(p, []byte{'\n'})
+		frame := make([]byte, 0, len(p)+1)
+		frame = append(frame, p...)
+		return append(frame, 0)
+	default:
+		frame := make([]byte, 0, len(p)+1)
+		frame = append(frame, p...)
+		if len(frame) == 0 || frame[len(frame)-1] != '\n' {
+			frame = append(frame, '\n')
+		}
+		return frame
+	}
+}
+
+// send sends pending events followed by frame. On error, the events which
+// could not be sent are left in pending except frame. Lock must be held.
+func (w *NetWriter) send(frame []byte) error {
+	if err := w.connect(); err != nil {
+		return err
+	}
+	for len(w.pending) > 0 {
+		if err := w.writeConn(w.pending[0]); err != nil {
+			return err
+		}
+		w.buffered -= len(w.pending[0])
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+	}
+	if frame == nil {
+		return nil
+	}
+	return w.writeConn(frame)
+}
+
+// connect establishes the connection if not already done and the backoff
+// delay has passed. Lock must be held.
+func (w *NetWriter) connect() error {
+	if w.conn != nil {
+		return nil
+	}
+	if time.Now().Before(w.nextDial) {
+		return errors.New("zerolog: NetWriter waiting to reconnect")
+	}
+	timeout := w.DialTimeout
+	if timeout <= 0 {
+		timeout = netWriterDialTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var conn net.Conn
+	var err error
+	switch {
+	case w.Dial != nil:
+		conn, err = w.Dial(ctx, w.Network, w.Address)
+	case w.TLSConfig != nil:
+		d := &tls.Dialer{Config: w.TLSConfig}
+		conn, err = d.DialContext(ctx, w.Network, w.Address)
+	default:
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, w.Network, w.Address)
+	}
+	if err != nil {
+		w.fail()
+		return err
+	}
+	w.conn = conn
+	w.backoff = 0
+	return nil
+}
+
+func (w *NetWriter) writeConn(frame []byte) error {
+	if w.WriteTimeout > 0 {
+		w.conn.SetWriteDeadline(time.Now().Add(w.WriteTimeout))
+	}
+	if _, err := w.conn.Write(frame); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		w.fail()
+		return err
+	}
+	return nil
+}
+
+// fail schedules the next connection attempt. Lock must be held.
+func (w *NetWriter) fail() {
+	minBackoff, maxBackoff := w.MinBackoff, w.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = netWriterMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = netWriterMaxBackoff
+	}
+	switch {
+	case w.backoff == 0:
+		w.backoff = minBackoff
+	case w.backoff < maxBackoff:
+		w.backoff *= 2
+	}
+	if w.backoff > maxBackoff {
+		w.backoff = maxBackoff
+	}
+	w.nextDial = time.Now().Add(w.backoff)
+}
+
+// buffer adds frame to the retry buffer, dropping the oldest events if
+// needed. Lock must be held.
+func (w *NetWriter) buffer(frame []byte) bool {
+	size := w.BufferSize
+	if size == 0 {
+		size = netWriterBufferSize
+	}
+	if len(frame) > size {
+		w.dropped.Add(1)
+		return false
+	}
+	for w.buffered+len(frame) > size {
+		w.buffered -= len(w.pending[0])
+		w.pending[0] = nil
+		w.pending = w.pending[1:]
+		w.dropped.Add(1)
+	}
+	w.pending = append(w.pending, frame)
+	w.buffered += len(frame)
+	return true
+}
+
+// Dropped returns the number of events dropped because the retry buffer was
+// full.
+func (w *NetWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush sends the buffered events, reconnecting as needed, until it succeeds
+// or ctx is done.
+func (w *NetWriter) Flush(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		if len(w.pending) == 0 {
+			w.mu.Unlock()
+			return nil
+		}
+		err := w.send(nil)
+		wait := time.Until(w.nextDial)
+		w.mu.Unlock()
+		if err == nil {
+			return nil
+		}
+		if wait <= 0 {
+			wait = time.Millisecond
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Close tries to send the buffered events once and closes the connection.
+// Events which could not be sent are dropped.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var err error
+	if len(w.pending) > 0 {
+		w.nextDial = time.Time{}
+		err = w.send(nil)
+		w.dropped.Add(uint64(len(w.pending)))
+		w.pending = nil
+		w.buffered = 0
+	}
+	if w.conn != nil {
+		if cerr := w.conn.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		w.conn = nil
+	}
+	return err
+}
+
+// datagramNetwork reports whether network sends each write in its own packet.
+func datagramNetwork(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	}
+	return false
+}
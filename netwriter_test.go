@@ -0,0 +1,274 @@
+package zerolog
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// acceptLines accepts a single connection on l and sends the lines read on
+// the returned channel.
+func acceptLines(t *testing.T, l net.Listener) <-chan string {
+	t.Helper()
+	lines := make(chan string, 100)
+	go func() {
+		defer close(lines)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s := bufio.NewScanner(conn)
+		for s.Scan() {
+			lines <- s.Text()
+		}
+	}()
+	return lines
+}
+
+func readLines(t *testing.T, lines <-chan string, n int) []string {
+	t.Helper()
+	var got []string
+	for len(got) < n {
+		select {
+		case line := <-lines:
+			got = append(got, line)
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for lines, got: %q", got)
+		}
+	}
+	return got
+}
+
+func TestNetWriterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	lines := acceptLines(t, l)
+
+	w := NewNetWriter("tcp", l.Addr().String())
+	defer w.Close()
+	w.Write([]byte(`{"a":1}` + "\n"))
+	w.Write([]byte(`{"a":2}`))
+	if got, want := readLines(t, lines, 2), []string{`{"a":1}`, `{"a":2}`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalid lines, got: %q, want: %q", got, want)
+	}
+}
+
+func TestNetWriterUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer l.Close()
+	lines := acceptLines(t, l)
+
+	w := NewNetWriter("unix", path)
+	defer w.Close()
+	w.Write([]byte("hello\n"))
+	if got := readLines(t, lines, 1); got[0] != "hello" {
+		t.Errorf("Invalid line: %q", got[0])
+	}
+}
+
+func TestNetWriterUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w := NewNetWriter("udp", pc.LocalAddr().String(), func(w *NetWriter) {
+		w.Framing = FramingNone
+	})
+	defer w.Close()
+	w.Write([]byte("hello\n"))
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 100)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "hello\n" {
+		t.Errorf("Invalid datagram: %q", got)
+	}
+}
+
+func TestNetWriterTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	l, err := tls.Listen("tcp", "127.0.0.1:0", srv.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	lines := acceptLines(t, l)
+
+	w := NewNetWriter("tcp", l.Addr().String(), func(w *NetWriter) {
+		w.TLSConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	})
+	defer w.Close()
+	if _, err := w.Write([]byte("secure\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLines(t, lines, 1); got[0] != "secure" {
+		t.Errorf("Invalid line: %q", got[0])
+	}
+}
+
+func TestNetWriterFraming(t *testing.T) {
+	tests := []struct {
+		framing Framing
+		in      string
+		want    string
+	}{
+		{FramingNewline, "abc", "abc\n"},
+		{FramingNewline, "abc\n", "abc\n"},
+		{FramingOctetCounting, "abc\n", "3 abc"},
+		{FramingLengthPrefix, "abc\n", "\x00\x00\x00\x03abc"},
+		{FramingNone, "abc\n", "abc\n"},
+		{FramingNullByte, "abc\n", "abc\x00"},
+	}
+	for _, tt := range tests {
+		w := &NetWriter{Framing: tt.framing}
+		if got := string(w.frame([]byte(tt.in))); got != tt.want {
+			t.Errorf("frame(%d, %q) = %q, want %q", tt.framing, tt.in, got, tt.want)
+		}
+	}
+}
+
+// pipeDialer returns connections whose remote ends are sent on conns. The
+// first failures dial attempts fail.
+type pipeDialer struct {
+	failures int
+	conns    chan net.Conn
+}
+
+func (d *pipeDialer) Dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if d.failures > 0 {
+		d.failures--
+		return nil, errors.New("connection refused")
+	}
+	c1, c2 := net.Pipe()
+	d.conns <- c2
+	return c1, nil
+}
+
+func TestNetWriterReconnect(t *testing.T) {
+	d := &pipeDialer{failures: 2, conns: make(chan net.Conn, 2)}
+	w := NewNetWriter("tcp", "example.com:1234", func(w *NetWriter) {
+		w.Dial = d.Dial
+		w.MinBackoff = 20 * time.Millisecond
+	})
+	defer w.Close()
+
+	// First dial fails: the event is buffered and a reconnect is scheduled.
+	if _, err := w.Write([]byte("1\n")); err != nil {
+		t.Fatal(err)
+	}
+	// Still within the backoff delay: buffered without dialing.
+	if _, err := w.Write([]byte("2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if d.failures != 1 {
+		t.Fatalf("Unexpected dial during backoff, failures left: %d", d.failures)
+	}
+	time.Sleep(25 * time.Millisecond)
+	// Second dial fails, backoff doubles.
+	w.Write([]byte("3\n"))
+	if w.backoff != 40*time.Millisecond {
+		t.Errorf("Invalid backoff: %s", w.backoff)
+	}
+
+	done := make(chan []string)
+	go func() {
+		conn := <-d.conns
+		done <- readLines(t, func() <-chan string {
+			lines := make(chan string, 10)
+			go func() {
+				s := bufio.NewScanner(conn)
+				for s.Scan() {
+					lines <- s.Text()
+				}
+			}()
+			return lines
+		}(), 3)
+	}()
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := <-done, []string{"1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalid lines, got: %q, want: %q", got, want)
+	}
+}
+
+func TestNetWriterZeroValueDefaults(t *testing.T) {
+	d := &pipeDialer{failures: 2, conns: make(chan net.Conn, 1)}
+	w := &NetWriter{Network: "tcp", Address: "example.com:1234", Dial: d.Dial}
+
+	for _, line := range []string{"1\n", "2\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Expected the event to be buffered, got: %v", err)
+		}
+	}
+	if d.failures != 1 {
+		t.Errorf("Unexpected dial during backoff, failures left: %d", d.failures)
+	}
+	if w.backoff != netWriterMinBackoff {
+		t.Errorf("Invalid backoff: %s", w.backoff)
+	}
+	if got := len(w.pending); got != 2 {
+		t.Errorf("Invalid number of pending events: %d", got)
+	}
+}
+
+func TestNetWriterBufferLimit(t *testing.T) {
+	w := NewNetWriter("tcp", "example.com:1234", func(w *NetWriter) {
+		w.Dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		}
+		w.BufferSize = 4
+	})
+	w.Write([]byte("1\n"))
+	w.Write([]byte("2\n"))
+	w.Write([]byte("3\n"))
+	if _, err := w.Write([]byte("too long\n")); err != ErrNetWriterBufferFull {
+		t.Errorf("Expected ErrNetWriterBufferFull, got: %v", err)
+	}
+	if got, want := w.pending, [][]byte{[]byte("2\n"), []byte("3\n")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Invalid pending events, got: %q, want: %q", got, want)
+	}
+	if got := w.Dropped(); got != 2 {
+		t.Errorf("Invalid dropped count: %d", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded error, got: %v", err)
+	}
+}
+
+func TestNetWriterNoBuffer(t *testing.T) {
+	w := NewNetWriter("tcp", "example.com:1234", func(w *NetWriter) {
+		w.Dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, io.ErrClosedPipe
+		}
+		w.BufferSize = -1
+	})
+	if _, err := w.Write([]byte("1\n")); err != io.ErrClosedPipe {
+		t.Errorf("Expected dial error, got: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// DefaultRingSize is the maximum number of events kept by a RingWriter
+// without limits.
+const DefaultRingSize = 1000
+
+// RingWriter is a LevelWriter keeping the last events written to it in
+// memory, like a flight recorder. The events can be dumped on demand, for
+// instance from an HTTP handler (see hlog.RingHandler) or when the program
+// crashes:
+//
+//	ring := zerolog.NewRingWriter()
+//	log := zerolog.New(zerolog.MultiLevelWriter(os.Stderr, ring))
+//	defer func() {
+//		if r := recover(); r != nil {
+//			ring.Dump(os.Stderr, zerolog.RingLevelFilter(zerolog.DebugLevel))
+//			panic(r)
+//		}
+//	}()
+//
+// Typically, the logger level is lower than the level of the main output, to
+// keep debug events in the ring only.
+type RingWriter struct {
+	// MaxEvents, if positive, is the maximum number of events kept.
+	MaxEvents int
+
+	// MaxBytes, if positive, is the maximum total size of the events kept.
+	// Events larger than MaxBytes are dropped.
+	//
+	// If neither MaxEvents nor MaxBytes are set, DefaultRingSize events are
+	// kept.
+	MaxBytes int
+
+	mu     sync.Mutex
+	events []ringEvent // circular buffer
+	head   int         // index of the oldest event
+	count  int
+	size   int
+}
+
+type ringEvent struct {
+	level Level
+	p     []byte
+}
+
+// NewRingWriter creates a RingWriter.
+func NewRingWriter(options ...func(w *RingWriter)) *RingWriter {
+	w := &RingWriter{}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements the io.Writer interface. The events are kept without
+// level.
+func (w *RingWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RingWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	maxEvents := w.MaxEvents
+	if maxEvents <= 0 && w.MaxBytes <= 0 {
+		maxEvents = DefaultRingSize
+	}
+	if w.MaxBytes > 0 && len(p) > w.MaxBytes {
+		return len(p), nil
+	}
+	for w.count > 0 && (maxEvents > 0 && w.count >= maxEvents || w.MaxBytes > 0 && w.size+len(p) > w.MaxBytes) {
+		w.pop()
+	}
+	if w.count == len(w.events) {
+		w.grow(maxEvents)
+	}
+	w.events[(w.head+w.count)%len(w.events)] = ringEvent{level: l, p: append([]byte(nil), p...)}
+	w.count++
+	w.size += len(p)
+	return len(p), nil
+}
+
+// pop removes the oldest event. w.mu must be held.
+func (w *RingWriter) pop() {
+	e := &w.events[w.head]
+	w.size -= len(e.p)
+	*e = ringEvent{}
+	w.head = (w.head + 1) % len(w.events)
+	w.count--
+}
+
+// grow makes room for more events, up to max if positive. w.mu must be held.
+func (w *RingWriter) grow(max int) {
+	n := 2*w.count + 16
+	if max > 0 && n > max {
+		n = max
+	}
+	events := make([]ringEvent, w.count, n)
+	for i := range events {
+		events[i] = w.events[(w.head+i)%len(w.events)]
+	}
+	w.events = events[:cap(events)]
+	w.head = 0
+}
+
+// Len returns the number of events kept.
+func (w *RingWriter) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// Reset removes all the events.
+func (w *RingWriter) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.events = nil
+	w.head, w.count, w.size = 0, 0, 0
+}
+
+// Events returns a copy of the events selected by all the filters, oldest
+// first, in the encoding of the logger.
+func (w *RingWriter) Events(filters ...RingFilter) [][]byte {
+	var events [][]byte
+	w.each(filters, func(_ Level, p []byte) error {
+		events = append(events, p)
+		return nil
+	})
+	return events
+}
+
+// Dump writes the events selected by all the filters to out, oldest first.
+// If out is a LevelWriter, the events are written with their level.
+func (w *RingWriter) Dump(out io.Writer, filters ...RingFilter) error {
+	lw, ok := out.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{out}
+	}
+	return w.each(filters, func(l Level, p []byte) error {
+		_, err := lw.WriteLevel(l, p)
+		return err
+	})
+}
+
+// each calls fn with the events selected by all the filters, oldest first.
+// The events are copied so fn is called without holding w.mu, and can use p
+// after it returns.
+func (w *RingWriter) each(filters []RingFilter, fn func(l Level, p []byte) error) error {
+	w.mu.Lock()
+	events := make([]ringEvent, w.count)
+	for i := range events {
+		events[i] = w.events[(w.head+i)%len(w.events)]
+	}
+	w.mu.Unlock()
+
+next:
+	for _, e := range events {
+		for _, f := range filters {
+			if !f(e.level, e.p) {
+				continue next
+			}
+		}
+		if err := fn(e.level, e.p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RingFilter selects the events of a RingWriter by their level and encoded
+// event p.
+type RingFilter func(l Level, p []byte) bool
+
+// RingLevelFilter returns a RingFilter selecting the events at level l or
+// above. Events written without level are selected.
+func RingLevelFilter(l Level) RingFilter {
+	return func(level Level, _ []byte) bool {
+		return level >= l
+	}
+}
+
+// RingFieldFilter returns a RingFilter selecting the events with a key field
+// equal to value once encoded in JSON, so 42 matches a field added with Int,
+// Int64 or Float64.
+func RingFieldFilter(key string, value interface{}) RingFilter {
+	want, err := jsonRoundTrip(value)
+	if err != nil {
+		return func(Level, []byte) bool { return false }
+	}
+	return func(_ Level, p []byte) bool {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(decodeIfBinaryToBytes(p), &fields); err != nil {
+			return false
+		}
+		got, ok := fields[key]
+		return ok && reflect.DeepEqual(got, want)
+	}
+}
+
+// jsonRoundTrip returns v as decoded by encoding/json after encoding it.
+func jsonRoundTrip(v interface{}) (interface{}, error) {
+	p, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var r interface{}
+	err = json.Unmarshal(p, &r)
+	return r, err
+}
@@ -0,0 +1,136 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ringMessages(events [][]byte) []string {
+	var msgs []string
+	for _, p := range events {
+		s := decodeIfBinaryToString(p)
+		i := strings.Index(s, `"message":"`)
+		msgs = append(msgs, strings.TrimSuffix(strings.TrimSpace(s[i+11:]), `"}`))
+	}
+	return msgs
+}
+
+func TestRingWriterMaxEvents(t *testing.T) {
+	ring := NewRingWriter(func(w *RingWriter) {
+		w.MaxEvents = 3
+	})
+	log := New(ring)
+	for i := 0; i < 50; i++ {
+		log.Info().Msg(fmt.Sprint(i))
+	}
+	if got, want := ringMessages(ring.Events()), []string{"47", "48", "49"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid events: got %v, want %v", got, want)
+	}
+	if ring.Len() != 3 {
+		t.Errorf("invalid length: %d", ring.Len())
+	}
+	ring.Reset()
+	if ring.Len() != 0 || len(ring.Events()) != 0 {
+		t.Error("expected no events after Reset")
+	}
+}
+
+func TestRingWriterMaxBytes(t *testing.T) {
+	ring := NewRingWriter(func(w *RingWriter) {
+		w.MaxBytes = 100
+	})
+	log := New(ring)
+	for i := 0; i < 10; i++ {
+		log.Info().Msg(fmt.Sprint(i))
+	}
+	size := 0
+	for _, p := range ring.Events() {
+		size += len(p)
+	}
+	if size > 100 || ring.Len() == 0 {
+		t.Errorf("invalid size: %d bytes in %d events", size, ring.Len())
+	}
+	if got := ringMessages(ring.Events()); got[len(got)-1] != "9" {
+		t.Errorf("invalid events: %v", got)
+	}
+
+	log.Info().Msg(strings.Repeat("x", 200))
+	if got := ringMessages(ring.Events()); got[len(got)-1] != "9" {
+		t.Errorf("expected event larger than MaxBytes to be dropped: %v", got)
+	}
+}
+
+func TestRingWriterDefaultSize(t *testing.T) {
+	ring := NewRingWriter()
+	log := New(ring)
+	for i := 0; i < DefaultRingSize+10; i++ {
+		log.Info().Msg("")
+	}
+	if ring.Len() != DefaultRingSize {
+		t.Errorf("invalid length: %d", ring.Len())
+	}
+}
+
+func TestRingWriterFilters(t *testing.T) {
+	ring := NewRingWriter()
+	log := New(ring)
+	log.Debug().Int("status", 200).Msg("a")
+	log.Warn().Int("status", 500).Str("user", "bob").Msg("b")
+	log.Error().Int("status", 500).Msg("c")
+	log.Log().Msg("d")
+
+	tests := []struct {
+		filters []RingFilter
+		want    []string
+	}{
+		{nil, []string{"a", "b", "c", "d"}},
+		{[]RingFilter{RingLevelFilter(WarnLevel)}, []string{"b", "c", "d"}},
+		{[]RingFilter{RingFieldFilter("status", 500)}, []string{"b", "c"}},
+		{[]RingFilter{RingFieldFilter("status", 500), RingFieldFilter("user", "bob")}, []string{"b"}},
+		{[]RingFilter{RingLevelFilter(ErrorLevel), RingFieldFilter("status", 200)}, nil},
+	}
+	for i, tt := range tests {
+		if got := ringMessages(ring.Events(tt.filters...)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d: got %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestRingWriterDump(t *testing.T) {
+	ring := NewRingWriter()
+	log := New(ring)
+	log.Info().Msg("a")
+	log.Error().Msg("b")
+
+	out := &bytes.Buffer{}
+	if err := ring.Dump(out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"a"}`+"\n"+`{"level":"error","message":"b"}`+"\n"; got != want {
+		t.Errorf("invalid dump:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	lw := &levelRecordingWriter{}
+	if err := ring.Dump(lw, RingLevelFilter(ErrorLevel)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lw.levels, []Level{ErrorLevel}) {
+		t.Errorf("invalid dumped levels: %v", lw.levels)
+	}
+}
+
+type levelRecordingWriter struct {
+	levels []Level
+}
+
+func (w *levelRecordingWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+func (w *levelRecordingWriter) WriteLevel(l Level, p []byte) (int, error) {
+	w.levels = append(w.levels, l)
+	return len(p), nil
+}
@@ -0,0 +1,205 @@
+package zerolog
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+)
+
+// ECSVersion is the Elastic Common Schema version set in the ecs.version
+// field by ECSSchema.
+const ECSVersion = "8.11.0"
+
+// Schema defines the names and format of the fields added by zerolog itself:
+// timestamp, level, message, error, stack and caller. It allows a logger to
+// use a layout expected by a log backend without changing the global
+// settings.
+//
+// Set a schema on a logger using Context.Schema. Loggers without schema use
+// the global settings (TimestampFieldName, LevelFieldName, etc.), as do the
+// field names left empty in the schema.
+type Schema struct {
+	TimestampFieldName  string
+	LevelFieldName      string
+	MessageFieldName    string
+	ErrorFieldName      string
+	ErrorStackFieldName string
+	CallerFieldName     string
+
+	// LevelFieldMarshalFunc, if not nil, is used instead of the global
+	// LevelFieldMarshalFunc to format the level.
+	LevelFieldMarshalFunc func(l Level) string
+
+	// CallerFunc, if not nil, is used to add the caller to the event instead
+	// of a CallerFieldName field formatted by CallerMarshalFunc.
+	CallerFunc func(e *Event, pc uintptr, file string, line int)
+
+	// Init, if not nil, is called by Context.Schema, to add static fields to
+	// the logger context.
+	Init func(c Context) Context
+
+	// Hook, if not nil, is run on each event before the logger hooks, to add
+	// dynamic fields.
+	Hook Hook
+}
+
+// ECSSchema lays out events using the Elastic Common Schema, for logs sent to
+// Elasticsearch or OpenSearch: @timestamp, log.level, message, error.message,
+// error.stack_trace, log.origin.* and ecs.version. ECS expects
+// error.stack_trace to be a string, so ErrorStackMarshaler should return a
+// string.
+var ECSSchema = &Schema{
+	TimestampFieldName:  "@timestamp",
+	LevelFieldName:      "log.level",
+	MessageFieldName:    "message",
+	ErrorFieldName:      "error.message",
+	ErrorStackFieldName: "error.stack_trace",
+	CallerFunc: func(e *Event, pc uintptr, file string, line int) {
+		e.Str("log.origin.file.name", file).Int("log.origin.file.line", line)
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			e.Str("log.origin.function", fn.Name())
+		}
+	},
+	Init: func(c Context) Context {
+		return c.Str("ecs.version", ECSVersion)
+	},
+}
+
+// GCPSchema lays out events for Google Cloud Logging structured logs:
+// severity, message, time and logging.googleapis.com/sourceLocation. Use
+// NewGCPSchema to also add trace information.
+var GCPSchema = NewGCPSchema("", nil)
+
+// GCPTraceFunc returns the trace id, span id and sampling decision of the
+// trace stored in ctx, if any.
+type GCPTraceFunc func(ctx context.Context) (traceID, spanID string, sampled bool)
+
+// NewGCPSchema creates a Schema laying out events for Google Cloud Logging
+// structured logs, like GCPSchema.
+//
+// If traceFunc is not nil, it is called with the context of each event (see
+// Event.Ctx and Context.Ctx) and the trace found is added with the
+// logging.googleapis.com/trace, logging.googleapis.com/spanId and
+// logging.googleapis.com/trace_sampled fields, so logs are correlated with
+// traces. projectID is used to build the trace resource name. For instance,
+// with OpenTelemetry:
+//
+//	schema := zerolog.NewGCPSchema("my-project", func(ctx context.Context) (string, string, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return "", "", false
+//		}
+//		return sc.TraceID().String(), sc.SpanID().String(), sc.IsSampled()
+//	})
+func NewGCPSchema(projectID string, traceFunc GCPTraceFunc) *Schema {
+	s := &Schema{
+		TimestampFieldName:    "time",
+		LevelFieldName:        "severity",
+		MessageFieldName:      "message",
+		ErrorFieldName:        "error",
+		ErrorStackFieldName:   "stack_trace",
+		LevelFieldMarshalFunc: gcpSeverity,
+		CallerFunc: func(e *Event, pc uintptr, file string, line int) {
+			// Line is an int64, encoded as a string in JSON.
+			loc := e.CreateDict().Str("file", file).Str("line", strconv.Itoa(line))
+			if fn := runtime.FuncForPC(pc); fn != nil {
+				loc.Str("function", fn.Name())
+			}
+			e.Dict("logging.googleapis.com/sourceLocation", loc)
+		},
+	}
+	if traceFunc != nil {
+		s.Hook = HookFunc(func(e *Event, level Level, msg string) {
+			traceID, spanID, sampled := traceFunc(e.GetCtx())
+			if traceID == "" {
+				return
+			}
+			if projectID != "" {
+				traceID = "projects/" + projectID + "/traces/" + traceID
+			}
+			e.Str("logging.googleapis.com/trace", traceID)
+			if spanID != "" {
+				e.Str("logging.googleapis.com/spanId", spanID)
+			}
+			e.Bool("logging.googleapis.com/trace_sampled", sampled)
+		})
+	}
+	return s
+}
+
+// gcpSeverity returns the Google Cloud Logging severity of a level.
+func gcpSeverity(l Level) string {
+	switch l {
+	case TraceLevel, DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel:
+		return "CRITICAL"
+	case PanicLevel:
+		return "ALERT"
+	}
+	return "DEFAULT"
+}
+
+// Schema sets the schema used by the logger. See Schema for more details.
+func (c Context) Schema(s *Schema) Context {
+	c.l.schema = s
+	if s != nil && s.Init != nil {
+		c = s.Init(c)
+	}
+	return c
+}
+
+func (s *Schema) timestampFieldName() string {
+	if s == nil || s.TimestampFieldName == "" {
+		return TimestampFieldName
+	}
+	return s.TimestampFieldName
+}
+
+func (s *Schema) levelFieldName() string {
+	if s == nil || s.LevelFieldName == "" {
+		return LevelFieldName
+	}
+	return s.LevelFieldName
+}
+
+func (s *Schema) levelFieldMarshalFunc() func(l Level) string {
+	if s == nil || s.LevelFieldMarshalFunc == nil {
+		return LevelFieldMarshalFunc
+	}
+	return s.LevelFieldMarshalFunc
+}
+
+func (s *Schema) messageFieldName() string {
+	if s == nil || s.MessageFieldName == "" {
+		return MessageFieldName
+	}
+	return s.MessageFieldName
+}
+
+func (s *Schema) errorFieldName() string {
+	if s == nil || s.ErrorFieldName == "" {
+		return ErrorFieldName
+	}
+	return s.ErrorFieldName
+}
+
+func (s *Schema) errorStackFieldName() string {
+	if s == nil || s.ErrorStackFieldName == "" {
+		return ErrorStackFieldName
+	}
+	return s.ErrorStackFieldName
+}
+
+func (s *Schema) callerFieldName() string {
+	if s == nil || s.CallerFieldName == "" {
+		return CallerFieldName
+	}
+	return s.CallerFieldName
+}
@@ -0,0 +1,169 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeSchemaOutput(t *testing.T, out *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(decodeIfBinaryToString(out.Bytes())), &m); err != nil {
+		t.Fatalf("invalid output %q: %v", out.String(), err)
+	}
+	return m
+}
+
+func TestSchemaECS(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	ErrorStackMarshaler = func(err error) interface{} {
+		return "stack"
+	}
+	defer func() {
+		ErrorStackMarshaler = nil
+	}()
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Schema(ECSSchema).Timestamp().Caller().Logger()
+	log.Error().Stack().Err(errors.New("boom")).Msg("failed")
+
+	got := decodeSchemaOutput(t, out)
+	file, _ := got["log.origin.file.name"].(string)
+	if !strings.HasSuffix(file, "schema_test.go") {
+		t.Errorf("invalid log.origin.file.name: %v", got["log.origin.file.name"])
+	}
+	if _, ok := got["log.origin.file.line"].(float64); !ok {
+		t.Errorf("invalid log.origin.file.line: %v", got["log.origin.file.line"])
+	}
+	if fn, _ := got["log.origin.function"].(string); !strings.HasSuffix(fn, "TestSchemaECS") {
+		t.Errorf("invalid log.origin.function: %v", got["log.origin.function"])
+	}
+	delete(got, "log.origin.file.name")
+	delete(got, "log.origin.file.line")
+	delete(got, "log.origin.function")
+	want := map[string]interface{}{
+		"@timestamp":        "2001-02-03T04:05:06Z",
+		"log.level":         "error",
+		"message":           "failed",
+		"error.message":     "boom",
+		"error.stack_trace": "stack",
+		"ecs.version":       ECSVersion,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSchemaGCP(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Schema(GCPSchema).Logger()
+	log.Warn().Caller().Msg("hello")
+
+	got := decodeSchemaOutput(t, out)
+	if got["severity"] != "WARNING" || got["message"] != "hello" {
+		t.Errorf("invalid output: %v", got)
+	}
+	loc, _ := got["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+	if file, _ := loc["file"].(string); !strings.HasSuffix(file, "schema_test.go") {
+		t.Errorf("invalid sourceLocation: %v", got["logging.googleapis.com/sourceLocation"])
+	}
+	if _, ok := loc["line"].(string); !ok {
+		t.Errorf("invalid sourceLocation line: %v", loc["line"])
+	}
+	if fn, _ := loc["function"].(string); !strings.HasSuffix(fn, "TestSchemaGCP") {
+		t.Errorf("invalid sourceLocation function: %v", loc["function"])
+	}
+
+	for level, want := range map[Level]string{
+		TraceLevel: "DEBUG",
+		DebugLevel: "DEBUG",
+		InfoLevel:  "INFO",
+		ErrorLevel: "ERROR",
+		FatalLevel: "CRITICAL",
+		PanicLevel: "ALERT",
+	} {
+		if got := gcpSeverity(level); got != want {
+			t.Errorf("gcpSeverity(%v) = %s, want %s", level, got, want)
+		}
+	}
+}
+
+type gcpTraceKey struct{}
+
+func TestSchemaGCPTrace(t *testing.T) {
+	schema := NewGCPSchema("my-project", func(ctx context.Context) (string, string, bool) {
+		if ids, ok := ctx.Value(gcpTraceKey{}).([2]string); ok {
+			return ids[0], ids[1], true
+		}
+		return "", "", false
+	})
+	ctx := context.WithValue(context.Background(), gcpTraceKey{}, [2]string{"abc", "123"})
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Schema(schema).Logger()
+	log.Error().Ctx(ctx).Msg("")
+	want := `{"severity":"ERROR","logging.googleapis.com/trace":"projects/my-project/traces/abc","logging.googleapis.com/spanId":"123","logging.googleapis.com/trace_sampled":true}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log.Log().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), "{}\n"; got != want {
+		t.Errorf("invalid output without trace: %v", got)
+	}
+}
+
+func TestSchemaInherited(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Schema(&Schema{
+		LevelFieldName:   "lvl",
+		MessageFieldName: "msg",
+		ErrorFieldName:   "err",
+	}).Logger()
+	log = log.With().Err(errors.New("ctx")).Logger().Output(out)
+	log.Info().Fields(map[string]interface{}{"a": 1}).Msg("m")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"lvl":"info","err":"ctx","a":1,"msg":"m"}`+"\n"; got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = New(out)
+	log.Info().Msg("m")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"m"}`+"\n"; got != want {
+		t.Errorf("global field names not used without schema:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSchemaPartial(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Schema(&Schema{LevelFieldName: "lvl"}).Timestamp().Caller().Logger()
+	log.Info().Err(errors.New("failed")).Msg("m")
+	m := decodeSchemaOutput(t, out)
+	for _, key := range []string{"lvl", TimestampFieldName, CallerFieldName, ErrorFieldName, MessageFieldName} {
+		if _, ok := m[key]; !ok {
+			t.Errorf("missing %s field in %v", key, m)
+		}
+	}
+	if _, ok := m[""]; ok {
+		t.Errorf("unexpected empty key in %v", m)
+	}
+}
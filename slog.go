@@ -3,6 +3,7 @@ package zerolog
 import (
 	"context"
 	"log/slog"
+	"runtime"
 	"time"
 )
 
@@ -10,9 +11,35 @@ import (
 // as the underlying log backend. This allows code that uses the standard
 // library's slog package to route log output through zerolog.
 type SlogHandler struct {
-	logger Logger
+	logger Logger // includes the attributes added with WithAttrs in its context
 	prefix string // group prefix for nested groups
-	attrs  []slog.Attr
+
+	// Set for handlers created with NewSlogHandlerWithOptions only.
+	opts       *SlogHandlerOptions
+	groups     []string // groups opened with WithGroup
+	groupAttrs []byte   // attributes added with WithAttrs in the open groups
+	openGroups int      // number of groups opened in groupAttrs
+}
+
+// SlogHandlerOptions are options for a SlogHandler created with
+// NewSlogHandlerWithOptions. They mirror slog.HandlerOptions.
+type SlogHandlerOptions struct {
+	// AddSource adds the source code position of the log statement, taken
+	// from the record's PC, as the caller field of the logger (see
+	// CallerFieldName and Schema).
+	AddSource bool
+
+	// Level, if not nil, reports the minimum level of the records to log. It
+	// replaces the level of the logger, and can be changed at run time with a
+	// slog.LevelVar. The global level still applies.
+	Level slog.Leveler
+
+	// ReplaceAttr, if not nil, is called to rewrite or remove each attribute
+	// before it is logged, as documented in slog.HandlerOptions. It is called
+	// for the built-in slog.TimeKey, slog.LevelKey, slog.SourceKey and
+	// slog.MessageKey attributes too; these are written using the field names
+	// of the logger unless ReplaceAttr changes their key.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
 }
 
 // NewSlogHandler creates a new slog.Handler that writes log records to the
@@ -22,6 +49,22 @@ func NewSlogHandler(logger Logger) *SlogHandler {
 	return &SlogHandler{logger: logger}
 }
 
+// NewSlogHandlerWithOptions creates a new slog.Handler that writes log
+// records to the given zerolog.Logger, like NewSlogHandler, with options
+// equivalent to the ones of slog's built-in handlers. opts may be nil.
+//
+// Unlike NewSlogHandler, which joins group names to attribute keys with a
+// dot, groups are written as nested objects, as slog.JSONHandler does.
+func NewSlogHandlerWithOptions(logger Logger, opts *SlogHandlerOptions) *SlogHandler {
+	if opts == nil {
+		opts = &SlogHandlerOptions{}
+	}
+	if opts.Level != nil && logger.GetLevel() != Disabled {
+		logger = logger.Level(TraceLevel)
+	}
+	return &SlogHandler{logger: logger, opts: opts}
+}
+
 // Enabled reports whether the handler handles records at the given level.
 // It mirrors Logger.should's level and writer checks (without sampling).
 func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
@@ -32,12 +75,18 @@ func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
 	if zl < GlobalLevel() {
 		return false
 	}
-	return zl >= h.logger.level
+	if h.opts != nil && h.opts.Level != nil {
+		return zl >= h.logger.GetLevel() && level >= h.opts.Level.Level()
+	}
+	return zl >= h.logger.GetLevel()
 }
 
 // Handle handles the Record. It converts the slog.Record into a zerolog event
 // and writes it using the underlying zerolog.Logger.
 func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.opts != nil {
+		return h.handleWithOptions(ctx, record)
+	}
 	zlevel := slogToZerologLevel(record.Level)
 	event := h.logger.WithLevel(zlevel)
 	if event == nil {
@@ -50,11 +99,6 @@ func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
 		event = event.Ctx(ctx)
 	}
 
-	// Add pre-attached attrs from WithAttrs
-	for _, a := range h.attrs {
-		event = appendSlogAttr(event, a, h.prefix)
-	}
-
 	// Add attrs from the record itself
 	record.Attrs(func(a slog.Attr) bool {
 		event = appendSlogAttr(event, a, h.prefix)
@@ -72,6 +116,85 @@ func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
 	return nil
 }
 
+// handleWithOptions handles the Record for handlers created with
+// NewSlogHandlerWithOptions.
+func (h *SlogHandler) handleWithOptions(ctx context.Context, record slog.Record) error {
+	replace := h.opts.ReplaceAttr
+	var levelAttr slog.Attr
+	replaceLevel := false
+	if replace != nil {
+		levelAttr = replace(nil, slog.Any(slog.LevelKey, record.Level))
+		l, ok := levelAttr.Value.Any().(slog.Level)
+		replaceLevel = !ok || l != record.Level || levelAttr.Key != slog.LevelKey
+	}
+
+	// A replaced level is added in place of the level field of the logger.
+	event := h.logger.newEventLevelField(slogToZerologLevel(record.Level), nil, !replaceLevel)
+	if event == nil {
+		return nil
+	}
+	if ctx != nil {
+		event = event.Ctx(ctx)
+	}
+	if replaceLevel {
+		event = appendSlogBuiltin(event, levelAttr, slog.LevelKey, event.schema.levelFieldName())
+	}
+
+	if !record.Time.IsZero() && !h.hasTimestampHook() {
+		a := slog.Time(slog.TimeKey, record.Time)
+		if replace != nil {
+			a = replace(nil, a)
+		}
+		event = appendSlogBuiltin(event, a, slog.TimeKey, event.schema.timestampFieldName())
+	}
+
+	if h.opts.AddSource && record.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		if replace == nil {
+			event.appendCaller(record.PC, f.File, f.Line)
+		} else {
+			a := replace(nil, slog.Any(slog.SourceKey, &slog.Source{Function: f.Function, File: f.File, Line: f.Line}))
+			if src, ok := a.Value.Any().(*slog.Source); ok && a.Key == slog.SourceKey {
+				event.appendCaller(record.PC, src.File, src.Line)
+			} else {
+				event = appendSlogBuiltin(event, a, slog.SourceKey, event.schema.callerFieldName())
+			}
+		}
+	}
+
+	if len(h.groupAttrs) > 1 {
+		event.buf = enc.AppendObjectData(event.buf, h.groupAttrs)
+	}
+	open := h.openGroups
+	if record.NumAttrs() > 0 {
+		// Groups without attributes are omitted, as with slog.JSONHandler.
+		for _, g := range h.groups[open:] {
+			event.buf = enc.AppendBeginMarker(enc.AppendKey(event.buf, g))
+		}
+		open = len(h.groups)
+		record.Attrs(func(a slog.Attr) bool {
+			event = h.appendAttr(event, a, h.groups)
+			return true
+		})
+	}
+	for i := 0; i < open; i++ {
+		event.buf = enc.AppendEndMarker(event.buf)
+	}
+
+	msg := record.Message
+	if replace != nil {
+		a := replace(nil, slog.String(slog.MessageKey, msg))
+		if a.Key == slog.MessageKey && a.Value.Kind() == slog.KindString {
+			msg = a.Value.String()
+		} else {
+			msg = ""
+			event = appendSlogBuiltin(event, a, slog.MessageKey, event.schema.messageFieldName())
+		}
+	}
+	event.Msg(msg)
+	return nil
+}
+
 // hasTimestampHook reports whether the logger has a timestampHook installed,
 // which would cause duplicate timestamp fields if we also emit record.Time.
 func (h *SlogHandler) hasTimestampHook() bool {
@@ -84,13 +207,44 @@ func (h *SlogHandler) hasTimestampHook() bool {
 }
 
 // WithAttrs returns a new Handler with the given attributes pre-attached.
-// These attributes will be included in every subsequent log record.
+// These attributes will be included in every subsequent log record. They are
+// encoded once into the context of the underlying logger, as Logger.With
+// does.
 func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	if len(attrs) == 0 {
 		return h
 	}
 	h2 := h.clone()
-	h2.attrs = append(h2.attrs, attrs...)
+	if len(h.groups) > 0 {
+		// The attributes belong to the open groups, which are closed when
+		// the record is handled.
+		e := h.logger.scratchEvent()
+		if len(h.groupAttrs) > 1 {
+			e.buf = append(e.buf[:0], h.groupAttrs...)
+		}
+		for _, g := range h.groups[h.openGroups:] {
+			e.buf = enc.AppendBeginMarker(enc.AppendKey(e.buf, g))
+		}
+		for _, a := range attrs {
+			e = h.appendAttr(e, a, h.groups)
+		}
+		h2.groupAttrs = append([]byte(nil), e.buf...)
+		h2.openGroups = len(h.groups)
+		putEvent(e)
+		return h2
+	}
+	c := h.logger.With()
+	e := c.l.scratchEvent()
+	for _, a := range attrs {
+		if h.opts != nil {
+			e = h.appendAttr(e, a, nil)
+		} else {
+			e = appendSlogAttr(e, a, h.prefix)
+		}
+	}
+	c.l.context = enc.AppendObjectData(c.l.context, e.buf)
+	putEvent(e)
+	h2.logger = c.Logger()
 	return h2
 }
 
@@ -101,6 +255,11 @@ func (h *SlogHandler) WithGroup(name string) slog.Handler {
 		return h
 	}
 	h2 := h.clone()
+	if h.opts != nil {
+		// Never share the backing array of h.groups.
+		h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+		return h2
+	}
 	if h2.prefix != "" {
 		h2.prefix = h2.prefix + "." + name
 	} else {
@@ -110,15 +269,14 @@ func (h *SlogHandler) WithGroup(name string) slog.Handler {
 }
 
 func (h *SlogHandler) clone() *SlogHandler {
-	h2 := &SlogHandler{
-		logger: h.logger,
-		prefix: h.prefix,
+	return &SlogHandler{
+		logger:     h.logger,
+		prefix:     h.prefix,
+		opts:       h.opts,
+		groups:     h.groups,
+		groupAttrs: h.groupAttrs,
+		openGroups: h.openGroups,
 	}
-	if len(h.attrs) > 0 {
-		h2.attrs = make([]slog.Attr, len(h.attrs))
-		copy(h2.attrs, h.attrs)
-	}
-	return h2
 }
 
 // slogToZerologLevel maps slog levels to zerolog levels.
@@ -204,9 +362,64 @@ func appendSlogAttr(event *Event, attr slog.Attr, prefix string) *Event {
 		return event
 	}
 
-	key := joinPrefix(prefix, attr.Key)
-	val := attr.Value
+	return appendSlogValue(event, joinPrefix(prefix, attr.Key), attr.Value)
+}
+
+// appendAttr appends a single slog.Attr to the zerolog event for handlers
+// created with NewSlogHandlerWithOptions: ReplaceAttr is applied and groups
+// are written as nested objects.
+func (h *SlogHandler) appendAttr(event *Event, attr slog.Attr, groups []string) *Event {
+	attr.Value = attr.Value.Resolve()
+	if replace := h.opts.ReplaceAttr; replace != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = replace(groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		attrs := attr.Value.Group()
+		if len(attrs) == 0 {
+			return event
+		}
+		// Attributes of groups with an empty key are inlined.
+		if attr.Key == "" {
+			for _, ga := range attrs {
+				event = h.appendAttr(event, ga, groups)
+			}
+			return event
+		}
+		event.buf = enc.AppendBeginMarker(enc.AppendKey(event.buf, attr.Key))
+		groups = append(groups[:len(groups):len(groups)], attr.Key)
+		for _, ga := range attrs {
+			event = h.appendAttr(event, ga, groups)
+		}
+		event.buf = enc.AppendEndMarker(event.buf)
+		return event
+	}
+
+	if attr.Key == "" {
+		return event
+	}
+	return appendSlogValue(event, attr.Key, attr.Value)
+}
+
+// appendSlogBuiltin appends a built-in attribute returned by ReplaceAttr to
+// the zerolog event. If its key is still slogKey, the zerolog field name is
+// used instead.
+func appendSlogBuiltin(event *Event, attr slog.Attr, slogKey, name string) *Event {
+	key := attr.Key
+	if key == slogKey {
+		key = name
+	}
+	if key == "" {
+		return event
+	}
+	return appendSlogValue(event, key, attr.Value.Resolve())
+}
 
+// appendSlogValue appends a single resolved, non group, slog.Value to the
+// zerolog event, handling type-specific encoding to avoid reflection where
+// possible.
+func appendSlogValue(event *Event, key string, val slog.Value) *Event {
 	switch val.Kind() {
 	case slog.KindString:
 		event = event.Str(key, val.String())
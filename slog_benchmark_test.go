@@ -0,0 +1,110 @@
+package zerolog_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+const slogBenchMessage = "Test logging, but use a somewhat realistic message length."
+
+func benchmarkSlogHandlers() map[string]slog.Handler {
+	return map[string]slog.Handler{
+		"slog.JSONHandler": slog.NewJSONHandler(io.Discard, nil),
+		"SlogHandler":      zerolog.NewSlogHandler(zerolog.New(io.Discard).With().Timestamp().Logger()),
+	}
+}
+
+func benchmarkSlogWithAttrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("a", "a"),
+		slog.Int("b", 1),
+		slog.Bool("c", true),
+		slog.Float64("d", 1.5),
+		slog.String("e", "e"),
+		slog.Int("f", 2),
+		slog.Bool("g", false),
+		slog.Float64("h", 2.5),
+		slog.String("i", "i"),
+		slog.Int("j", 3),
+	}
+}
+
+func BenchmarkSlog(b *testing.B) {
+	ctx := context.Background()
+	for name, h := range benchmarkSlogHandlers() {
+		b.Run(name+"/NoAttrs", func(b *testing.B) {
+			logger := slog.New(h)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.LogAttrs(ctx, slog.LevelInfo, slogBenchMessage)
+			}
+		})
+		b.Run(name+"/RecordAttrs", func(b *testing.B) {
+			logger := slog.New(h)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.LogAttrs(ctx, slog.LevelInfo, slogBenchMessage,
+					slog.String("a", "a"),
+					slog.Int("b", 1),
+					slog.Bool("c", true),
+					slog.Float64("d", 1.5),
+					slog.String("e", "e"),
+				)
+			}
+		})
+		b.Run(name+"/WithAttrs", func(b *testing.B) {
+			logger := slog.New(h.WithAttrs(benchmarkSlogWithAttrs()))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.LogAttrs(ctx, slog.LevelInfo, slogBenchMessage)
+			}
+		})
+		b.Run(name+"/WithAttrsParallel", func(b *testing.B) {
+			logger := slog.New(h.WithAttrs(benchmarkSlogWithAttrs()))
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.LogAttrs(ctx, slog.LevelInfo, slogBenchMessage)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkSlogNative(b *testing.B) {
+	b.Run("NoAttrs", func(b *testing.B) {
+		logger := zerolog.New(io.Discard).With().Timestamp().Logger()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Msg(slogBenchMessage)
+		}
+	})
+	b.Run("RecordAttrs", func(b *testing.B) {
+		logger := zerolog.New(io.Discard).With().Timestamp().Logger()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Str("a", "a").Int("b", 1).Bool("c", true).Float64("d", 1.5).Str("e", "e").Msg(slogBenchMessage)
+		}
+	})
+	b.Run("WithAttrs", func(b *testing.B) {
+		logger := zerolog.New(io.Discard).With().Timestamp().
+			Str("a", "a").Int("b", 1).Bool("c", true).Float64("d", 1.5).Str("e", "e").
+			Int("f", 2).Bool("g", false).Float64("h", 2.5).Str("i", "i").Int("j", 3).
+			Logger()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Msg(slogBenchMessage)
+		}
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
 	"testing"
 	"time"
 
@@ -557,3 +560,188 @@ func TestSlogHandler_TimestampWithoutHook(t *testing.T) {
 		t.Error("expected timestamp field when logger has no timestamp hook")
 	}
 }
+
+type countingLogValuer struct {
+	n *int
+}
+
+func (v countingLogValuer) LogValue() slog.Value {
+	*v.n++
+	return slog.StringValue("resolved")
+}
+
+func TestSlogHandler_WithAttrsEncodedOnce(t *testing.T) {
+	var buf bytes.Buffer
+	var n int
+	logger := slog.New(zerolog.NewSlogHandler(zerolog.New(&buf)).WithAttrs([]slog.Attr{
+		slog.Any("v", countingLogValuer{&n}),
+	}))
+
+	for _, msg := range []string{"a", "b"} {
+		buf.Reset()
+		logger.Info(msg)
+		if m := decodeJSON(t, &buf); m["v"] != "resolved" || m["message"] != msg {
+			t.Errorf("unexpected output: %v", m)
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected WithAttrs attrs to be resolved once, got %d", n)
+	}
+}
+
+func TestSlogHandler_WithAttrsBeforeGroup(t *testing.T) {
+	var buf bytes.Buffer
+	handler := zerolog.NewSlogHandler(zerolog.New(&buf))
+	logger := slog.New(handler.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g"))
+
+	logger.Info("test", "b", 2)
+
+	m := decodeJSON(t, &buf)
+	if m["a"] != float64(1) {
+		t.Errorf("expected a=1 outside of the group, got %v", m)
+	}
+	if m["g.b"] != float64(2) {
+		t.Errorf("expected g.b=2, got %v", m)
+	}
+}
+
+func TestSlogHandlerWithOptions_MatchesJSONHandler(t *testing.T) {
+	// Drop the built-in attributes which are formatted differently.
+	opts := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}
+	var zbuf, sbuf bytes.Buffer
+	var loggers []*slog.Logger
+	for _, h := range []slog.Handler{
+		zerolog.NewSlogHandlerWithOptions(zerolog.New(&zbuf), &zerolog.SlogHandlerOptions{ReplaceAttr: opts.ReplaceAttr}),
+		slog.NewJSONHandler(&sbuf, opts),
+	} {
+		loggers = append(loggers, slog.New(h).With("a", 1).WithGroup("g").With("b", 2).WithGroup("h"))
+	}
+
+	for _, log := range []func(l *slog.Logger){
+		func(l *slog.Logger) {
+			l.Info("with attrs", "c", 3, slog.Group("i", "d", 4), slog.Group("", "e", 5), slog.Group("empty"))
+		},
+		func(l *slog.Logger) {
+			l.Info("without attrs")
+		},
+	} {
+		zbuf.Reset()
+		sbuf.Reset()
+		for _, l := range loggers {
+			log(l)
+		}
+		got := decodeJSON(t, &zbuf)
+		want := decodeJSON(t, &sbuf)
+		want["message"] = want["msg"]
+		delete(want, "msg")
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected output:\ngot:  %v\nwant: %v", got, want)
+		}
+	}
+}
+
+func TestSlogHandlerWithOptions_ReplaceAttr(t *testing.T) {
+	var buf bytes.Buffer
+	var groups [][]string
+	handler := zerolog.NewSlogHandlerWithOptions(zerolog.New(&buf), &zerolog.SlogHandlerOptions{
+		ReplaceAttr: func(g []string, a slog.Attr) slog.Attr {
+			groups = append(groups, append([]string(nil), g...))
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.Attr{}
+			case slog.LevelKey:
+				return slog.String("severity", a.Value.Any().(slog.Level).String())
+			case slog.MessageKey:
+				return slog.String("text", a.Value.String())
+			case "password":
+				return slog.String("password", "REDACTED")
+			}
+			return a
+		},
+	})
+	slog.New(handler).WithGroup("user").Warn("login", "name", "bob", "password", "secret")
+
+	m := decodeJSON(t, &buf)
+	want := map[string]interface{}{
+		"severity": "WARN",
+		"text":     "login",
+		"user":     map[string]interface{}{"name": "bob", "password": "REDACTED"},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("unexpected output:\ngot:  %v\nwant: %v", m, want)
+	}
+	wantGroups := [][]string{nil, nil, {"user"}, {"user"}, nil}
+	if !reflect.DeepEqual(groups, wantGroups) {
+		t.Errorf("unexpected ReplaceAttr groups: %v", groups)
+	}
+}
+
+func TestSlogHandlerWithOptions_ReplaceLevelKeepsLoggerFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf).Named("svc").With().Str("app", "api").Logger()
+	handler := zerolog.NewSlogHandlerWithOptions(logger, &zerolog.SlogHandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			switch a.Key {
+			case slog.TimeKey:
+				return slog.Attr{}
+			case slog.LevelKey:
+				return slog.String("severity", a.Value.Any().(slog.Level).String())
+			}
+			return a
+		},
+	})
+	slog.New(handler).Info("started")
+
+	m := decodeJSON(t, &buf)
+	want := map[string]interface{}{
+		zerolog.LoggerNameFieldName: "svc",
+		"app":                       "api",
+		"severity":                  "INFO",
+		"message":                   "started",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("unexpected output:\ngot:  %v\nwant: %v", m, want)
+	}
+}
+
+func TestSlogHandlerWithOptions_AddSource(t *testing.T) {
+	var buf bytes.Buffer
+	handler := zerolog.NewSlogHandlerWithOptions(zerolog.New(&buf), &zerolog.SlogHandlerOptions{AddSource: true})
+	_, file, line, _ := runtime.Caller(0)
+	slog.New(handler).Info("test")
+
+	m := decodeJSON(t, &buf)
+	if want := file + ":" + strconv.Itoa(line+1); m[zerolog.CallerFieldName] != want {
+		t.Errorf("expected caller %s, got %v", want, m[zerolog.CallerFieldName])
+	}
+}
+
+func TestSlogHandlerWithOptions_Level(t *testing.T) {
+	var buf bytes.Buffer
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	logger := slog.New(zerolog.NewSlogHandlerWithOptions(zerolog.New(&buf).Level(zerolog.ErrorLevel), &zerolog.SlogHandlerOptions{Level: &level}))
+
+	logger.Info("filtered")
+	if buf.Len() != 0 {
+		t.Errorf("expected info to be filtered, got %s", decodeOutput(&buf))
+	}
+	logger.Warn("logged")
+	if m := decodeJSON(t, &buf); m["message"] != "logged" {
+		t.Errorf("unexpected output: %v", m)
+	}
+
+	buf.Reset()
+	level.Set(slog.LevelDebug)
+	logger.Debug("logged")
+	if m := decodeJSON(t, &buf); m["level"] != "debug" {
+		t.Errorf("unexpected output: %v", m)
+	}
+}
@@ -0,0 +1,199 @@
+package zerolog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// contextLevelWriter is implemented by writers using the context of the
+// events (see Event.Ctx and Context.Ctx).
+type contextLevelWriter interface {
+	writeLevelContext(ctx context.Context, l Level, p []byte) (n int, err error)
+}
+
+// SlogWriter is a LevelWriter delivering zerolog events to a slog.Handler. It
+// is the reverse of SlogHandler: it lets code using the zerolog API log
+// through a slog.Handler:
+//
+//	log := zerolog.New(zerolog.NewSlogWriter(handler)).With().Timestamp().Logger()
+//
+// Each event is converted into a slog.Record: the level, timestamp and
+// message fields are used as the record level, time and message, and the
+// other fields are added as attributes, keeping their JSON types. Numbers
+// are Int64 when possible and Float64 otherwise, and objects are groups.
+//
+// The context of the event (see Event.Ctx and Context.Ctx) is passed to the
+// handler when the SlogWriter is the output of the logger, but not when it is
+// wrapped by another writer like MultiLevelWriter.
+type SlogWriter struct {
+	// Handler is the slog.Handler the records are sent to.
+	Handler slog.Handler
+}
+
+// NewSlogWriter creates a SlogWriter sending the records to h.
+func NewSlogWriter(h slog.Handler, options ...func(w *SlogWriter)) *SlogWriter {
+	w := &SlogWriter{
+		Handler: h,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Write implements the io.Writer interface. The level is read from the event
+// level field.
+func (w *SlogWriter) Write(p []byte) (n int, err error) {
+	return w.write(context.Background(), NoLevel, false, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *SlogWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(context.Background(), l, true, p)
+}
+
+func (w *SlogWriter) writeLevelContext(ctx context.Context, l Level, p []byte) (n int, err error) {
+	return w.write(ctx, l, true, p)
+}
+
+func (w *SlogWriter) write(ctx context.Context, l Level, leveled bool, p []byte) (n int, err error) {
+	fields, err := decodeOrderedFields(decodeIfBinaryToBytes(p))
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	var ts time.Time
+	var msg string
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		switch f.key {
+		case LevelFieldName:
+			if !leveled {
+				if s, ok := f.string(); ok {
+					l, _ = ParseLevel(s)
+				}
+			}
+			continue
+		case MessageFieldName:
+			if s, ok := f.string(); ok {
+				msg = s
+				continue
+			}
+		case TimestampFieldName:
+			if t, ok := parseSlogTime(f.value); ok {
+				ts = t
+				continue
+			}
+		}
+		v, err := slogValue(f.value)
+		if err != nil {
+			return 0, fmt.Errorf("cannot decode field %s: %s", f.key, err)
+		}
+		attrs = append(attrs, slog.Attr{Key: f.key, Value: v})
+	}
+
+	level := zerologToSlogLevel(l)
+	if !w.Handler.Enabled(ctx, level) {
+		return len(p), nil
+	}
+	r := slog.NewRecord(ts, level, msg, 0)
+	r.AddAttrs(attrs...)
+	if err := w.Handler.Handle(ctx, r); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// slogValue converts a JSON value into a slog.Value of the same type.
+func slogValue(raw json.RawMessage) (slog.Value, error) {
+	if len(raw) == 0 {
+		return slog.AnyValue(nil), nil
+	}
+	switch raw[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.StringValue(s), nil
+	case '{':
+		fields, err := decodeOrderedFields(raw)
+		if err != nil {
+			return slog.Value{}, err
+		}
+		attrs := make([]slog.Attr, 0, len(fields))
+		for _, f := range fields {
+			v, err := slogValue(f.value)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			attrs = append(attrs, slog.Attr{Key: f.key, Value: v})
+		}
+		return slog.GroupValue(attrs...), nil
+	case '[':
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return slog.Value{}, err
+		}
+		vals := make([]interface{}, 0, len(elems))
+		for _, e := range elems {
+			v, err := slogValue(e)
+			if err != nil {
+				return slog.Value{}, err
+			}
+			vals = append(vals, v.Any())
+		}
+		return slog.AnyValue(vals), nil
+	case 't', 'f':
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return slog.Value{}, err
+		}
+		return slog.BoolValue(b), nil
+	case 'n':
+		return slog.AnyValue(nil), nil
+	}
+	s := string(raw)
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return slog.Int64Value(i), nil
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return slog.Uint64Value(u), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return slog.Value{}, err
+	}
+	return slog.Float64Value(f), nil
+}
+
+// parseSlogTime parses a timestamp field formatted with TimeFieldFormat.
+func parseSlogTime(raw json.RawMessage) (time.Time, bool) {
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return time.Time{}, false
+		}
+		t, err := time.Parse(TimeFieldFormat, s)
+		return t, err == nil
+	}
+	i, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch TimeFieldFormat {
+	case TimeFormatUnixNano:
+		return time.Unix(0, i), true
+	case TimeFormatUnixMicro:
+		return time.UnixMicro(i), true
+	case TimeFormatUnixMs:
+		return time.UnixMilli(i), true
+	case TimeFormatUnix:
+		return time.Unix(i, 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,147 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordingSlogHandler records the records and contexts it handles.
+type recordingSlogHandler struct {
+	level   slog.Level
+	records []slog.Record
+	ctxs    []context.Context
+}
+
+func (h *recordingSlogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level
+}
+
+func (h *recordingSlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	h.ctxs = append(h.ctxs, ctx)
+	return nil
+}
+
+func (h *recordingSlogHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingSlogHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestSlogWriter(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+
+	h := &recordingSlogHandler{level: slog.LevelDebug}
+	log := New(NewSlogWriter(h)).With().Timestamp().Str("svc", "api").Logger()
+	log.Warn().
+		Int("n", 42).
+		Float64("f", 1.5).
+		Bool("ok", true).
+		Err(errors.New("boom")).
+		Dict("req", Dict().Str("method", "GET").Int("status", 200)).
+		Ints("ids", []int{1, 2}).
+		Interface("nil", nil).
+		Msg("hello")
+
+	if len(h.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(h.records))
+	}
+	r := h.records[0]
+	if r.Level != slog.LevelWarn || r.Message != "hello" || !r.Time.Equal(TimestampFunc()) {
+		t.Errorf("invalid record: %v %q %v", r.Level, r.Message, r.Time)
+	}
+	got := map[string]interface{}{}
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Value.Kind() == slog.KindGroup {
+			g := map[string]interface{}{}
+			for _, ga := range a.Value.Group() {
+				g[ga.Key] = ga.Value.Any()
+			}
+			got[a.Key] = g
+			return true
+		}
+		got[a.Key] = a.Value.Any()
+		return true
+	})
+	want := map[string]interface{}{
+		"svc":   "api",
+		"n":     int64(42),
+		"f":     1.5,
+		"ok":    true,
+		"error": "boom",
+		"req":   map[string]interface{}{"method": "GET", "status": int64(200)},
+		"ids":   []interface{}{int64(1), int64(2)},
+		"nil":   nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid attrs:\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+type slogWriterCtxKey struct{}
+
+func TestSlogWriterContext(t *testing.T) {
+	h := &recordingSlogHandler{}
+	log := New(NewSlogWriter(h))
+	ctx := context.WithValue(context.Background(), slogWriterCtxKey{}, "v")
+
+	log.Info().Ctx(ctx).Msg("")
+	ctxLog := log.With().Ctx(ctx).Logger()
+	ctxLog.Info().Msg("")
+	log.Info().Msg("")
+
+	if len(h.ctxs) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(h.ctxs))
+	}
+	for i, want := range []interface{}{"v", "v", nil} {
+		if got := h.ctxs[i].Value(slogWriterCtxKey{}); got != want {
+			t.Errorf("record %d: invalid context value %v", i, got)
+		}
+	}
+}
+
+func TestSlogWriterLevel(t *testing.T) {
+	h := &recordingSlogHandler{level: slog.LevelWarn}
+	log := New(NewSlogWriter(h))
+	log.Info().Msg("filtered")
+	log.Error().Msg("sent")
+	if len(h.records) != 1 || h.records[0].Message != "sent" {
+		t.Errorf("invalid records: %v", h.records)
+	}
+
+	// Without WriteLevel, the level is read from the event.
+	w := NewSlogWriter(h)
+	if _, err := w.Write([]byte(`{"level":"error","message":"m"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if r := h.records[len(h.records)-1]; r.Level != slog.LevelError {
+		t.Errorf("invalid level: %v", r.Level)
+	}
+}
+
+func TestSlogWriterJSONHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(NewSlogWriter(slog.NewJSONHandler(out, nil)))
+	log.Info().Dict("req", Dict().Int("status", 200)).Msg("done")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"level": "INFO",
+		"msg":   "done",
+		"req":   map[string]interface{}{"status": float64(200)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,279 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// DefaultRFC5424SDID is the default SD-ID of the structured data element
+// holding the event fields. 32473 is the private enterprise number reserved
+// for documentation by RFC 5612.
+const DefaultRFC5424SDID = "zerolog@32473"
+
+// rfc5424TimeFormat is the TIMESTAMP format, limited to microseconds by the
+// RFC.
+const rfc5424TimeFormat = "2006-01-02T15:04:05.999999Z07:00"
+
+// RFC5424Writer is a LevelWriter formatting events as RFC 5424 syslog
+// messages. The event message is used as the syslog MSG and the other fields
+// are sent as parameters of a single STRUCTURED-DATA element.
+//
+// RFC5424Writer does not depend on log/syslog and works with any io.Writer.
+// Each event is written with a single call to Out; use NewSyslogNetWriter to
+// send messages to a syslog server over UDP, TCP, TLS or a Unix socket with
+// the proper framing.
+type RFC5424Writer struct {
+	// Out is the destination writer.
+	Out io.Writer
+
+	// Facility is the syslog facility code as defined in RFC 5424 section
+	// 6.2.1. Default is 1 (user-level messages).
+	Facility int
+
+	// Hostname is the HOSTNAME header field. Default is os.Hostname().
+	Hostname string
+
+	// AppName is the APP-NAME header field. Default is the program name.
+	AppName string
+
+	// ProcID is the PROCID header field. Default is the process id.
+	ProcID string
+
+	// MsgID is the MSGID header field. Default is empty (nil value).
+	MsgID string
+
+	// SDID is the SD-ID of the structured data element holding the event
+	// fields. Default is DefaultRFC5424SDID. If empty, fields are not sent.
+	SDID string
+}
+
+// NewRFC5424Writer creates an RFC5424Writer writing to out.
+func NewRFC5424Writer(out io.Writer, options ...func(w *RFC5424Writer)) *RFC5424Writer {
+	hostname, _ := os.Hostname()
+	w := &RFC5424Writer{
+		Out:      out,
+		Facility: 1,
+		Hostname: hostname,
+		AppName:  filepath.Base(os.Args[0]),
+		ProcID:   strconv.Itoa(os.Getpid()),
+		SDID:     DefaultRFC5424SDID,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// NewSyslogNetWriter creates a NetWriter sending messages to a syslog server,
+// using octet counting framing (RFC 6587) on stream networks and one message
+// per datagram otherwise. Use options to set a TLSConfig for RFC 5425 syslog
+// over TLS.
+func NewSyslogNetWriter(network, address string, options ...func(w *NetWriter)) *NetWriter {
+	framing := FramingOctetCounting
+	if datagramNetwork(network) {
+		framing = FramingNone
+	}
+	return NewNetWriter(network, address, func(w *NetWriter) {
+		w.Framing = framing
+		for _, opt := range options {
+			opt(w)
+		}
+	})
+}
+
+// syslogSeverity returns the syslog severity matching a zerolog level, as used
+// by SyslogLevelWriter.
+func syslogSeverity(level Level) int {
+	switch level {
+	case TraceLevel, DebugLevel:
+		return 7 // debug
+	case WarnLevel:
+		return 4 // warning
+	case ErrorLevel:
+		return 3 // err
+	case FatalLevel:
+		return 0 // emerg
+	case PanicLevel:
+		return 2 // crit
+	default:
+		return 6 // info
+	}
+}
+
+// Write implements the io.Writer interface. The severity is read from the
+// event level field.
+func (w *RFC5424Writer) Write(p []byte) (n int, err error) {
+	return w.write(NoLevel, false, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RFC5424Writer) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.write(l, true, p)
+}
+
+func (w *RFC5424Writer) write(l Level, leveled bool, p []byte) (n int, err error) {
+	fields, err := decodeOrderedFields(decodeIfBinaryToBytes(p))
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	ts := time.Now()
+	var msg string
+	sd := make([]byte, 0, len(p)+16)
+	for _, f := range fields {
+		switch f.key {
+		case LevelFieldName:
+			if !leveled {
+				if s, ok := f.string(); ok {
+					l, _ = ParseLevel(s)
+				}
+			}
+			continue
+		case MessageFieldName:
+			msg, _ = f.string()
+			continue
+		case TimestampFieldName:
+			if s, ok := f.string(); ok {
+				if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					ts = t
+					continue
+				}
+			}
+		}
+		if w.SDID == "" {
+			continue
+		}
+		sd = append(sd, ' ')
+		sd = appendSyslogName(sd, f.key, 32)
+		sd = append(sd, '=', '"')
+		if s, ok := f.string(); ok {
+			sd = appendSyslogParamValue(sd, s)
+		} else {
+			sd = appendSyslogParamValue(sd, string(f.value))
+		}
+		sd = append(sd, '"')
+	}
+
+	buf := make([]byte, 0, len(sd)+len(msg)+128)
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(w.Facility*8+syslogSeverity(l)), 10)
+	buf = append(buf, ">1 "...)
+	buf = ts.AppendFormat(buf, rfc5424TimeFormat)
+	buf = append(buf, ' ')
+	buf = appendSyslogName(buf, w.Hostname, 255)
+	buf = append(buf, ' ')
+	buf = appendSyslogName(buf, w.AppName, 48)
+	buf = append(buf, ' ')
+	buf = appendSyslogName(buf, w.ProcID, 128)
+	buf = append(buf, ' ')
+	buf = appendSyslogName(buf, w.MsgID, 32)
+	buf = append(buf, ' ')
+	if len(sd) > 0 {
+		buf = append(buf, '[')
+		buf = appendSyslogName(buf, w.SDID, 32)
+		buf = append(buf, sd...)
+		buf = append(buf, ']')
+	} else {
+		buf = append(buf, '-')
+	}
+	if msg != "" {
+		buf = append(buf, ' ')
+		buf = append(buf, msg...)
+	}
+
+	if _, err = w.Out.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close calls the Close method of Out if it implements io.Closer. Otherwise
+// does nothing.
+func (w *RFC5424Writer) Close() error {
+	if closer, ok := w.Out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// appendSyslogName appends s as a header field or SD name: only printable
+// US-ASCII characters, at most max of them, or the nil value if s is empty.
+// Characters not allowed are replaced by '_'.
+func appendSyslogName(dst []byte, s string, max int) []byte {
+	if s == "" {
+		return append(dst, '-')
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendSyslogParamValue appends s as an SD PARAM-VALUE, escaping '"', '\'
+// and ']'.
+func appendSyslogParamValue(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\\', ']':
+			dst = append(dst, '\\')
+		}
+		dst = append(dst, s[i])
+	}
+	return dst
+}
+
+// orderedField is a top level field of a JSON event.
+type orderedField struct {
+	key   string
+	value json.RawMessage
+}
+
+// string returns the field value if it is a JSON string.
+func (f orderedField) string() (string, bool) {
+	if len(f.value) == 0 || f.value[0] != '"' {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(f.value, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// decodeOrderedFields decodes the top level fields of a JSON event, keeping
+// their order.
+func decodeOrderedFields(p []byte) ([]orderedField, error) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	if t, err := d.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, fmt.Errorf("unexpected %v, expecting an object", t)
+	}
+	var fields []orderedField
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		var f orderedField
+		f.key, _ = t.(string)
+		if err := d.Decode(&f.value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
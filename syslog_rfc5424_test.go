@@ -0,0 +1,122 @@
+package zerolog
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func newTestRFC5424Writer(out io.Writer, options ...func(w *RFC5424Writer)) *RFC5424Writer {
+	return NewRFC5424Writer(out, append([]func(w *RFC5424Writer){func(w *RFC5424Writer) {
+		w.Hostname = "host"
+		w.AppName = "app"
+		w.ProcID = "42"
+	}}, options...)...)
+}
+
+func TestRFC5424Writer(t *testing.T) {
+	tests := []struct {
+		name    string
+		options func(w *RFC5424Writer)
+		event   string
+		want    string
+	}{
+		{
+			"fields",
+			nil,
+			`{"level":"warn","time":"2024-01-02T03:04:05.123Z","a":"b","n":1,"o":{"x":[1,2]},"message":"hello world"}`,
+			`<12>1 2024-01-02T03:04:05.123Z host app 42 - [zerolog@32473 a="b" n="1" o="{\"x\":[1,2\]}"] hello world`,
+		},
+		{
+			"escaping",
+			nil,
+			`{"level":"error","key with space":"a\"b\\c]d","message":"x"}`,
+			`<11>1 TS host app 42 - [zerolog@32473 key_with_space="a\"b\\c\]d"] x`,
+		},
+		{
+			"no fields",
+			func(w *RFC5424Writer) {
+				w.Facility = 16
+				w.MsgID = "req"
+			},
+			`{"level":"debug","message":"m"}`,
+			`<135>1 TS host app 42 req - m`,
+		},
+		{
+			"no SDID",
+			func(w *RFC5424Writer) {
+				w.SDID = ""
+			},
+			`{"a":"b"}`,
+			`<14>1 TS host app 42 - -`,
+		},
+	}
+	ts := regexp.MustCompile(`^(<\d+>1) [^ ]+`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			var w *RFC5424Writer
+			if tt.options != nil {
+				w = newTestRFC5424Writer(out, tt.options)
+			} else {
+				w = newTestRFC5424Writer(out)
+			}
+			if _, err := w.Write([]byte(tt.event + "\n")); err != nil {
+				t.Fatal(err)
+			}
+			got := out.String()
+			if strings.Contains(tt.want, " TS ") {
+				// Timestamp is the current time.
+				got = ts.ReplaceAllString(got, "$1 TS")
+			}
+			if got != tt.want {
+				t.Errorf("invalid message:\ngot:  %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRFC5424WriterLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(newTestRFC5424Writer(out))
+	log.Error().Str("foo", "bar").Msg("failed")
+	want := regexp.MustCompile(`^<11>1 [^ ]+ host app 42 - \[zerolog@32473 foo="bar"\] failed$`)
+	if got := out.String(); !want.MatchString(got) {
+		t.Errorf("invalid message: %s", got)
+	}
+}
+
+func TestSyslogNetWriter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	got := make(chan string)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		s, _ := r.ReadString(' ')
+		b := make([]byte, len("<14>1 2024-01-02T03:04:05Z host app 42 - - hi"))
+		io.ReadFull(r, b)
+		got <- s + string(b)
+	}()
+
+	w := newTestRFC5424Writer(NewSyslogNetWriter("tcp", l.Addr().String()))
+	defer w.Close()
+	w.Write([]byte(`{"time":"2024-01-02T03:04:05Z","message":"hi"}` + "\n"))
+	if got, want := <-got, "45 <14>1 2024-01-02T03:04:05Z host app 42 - - hi"; got != want {
+		t.Errorf("invalid frame:\ngot:  %s\nwant: %s", got, want)
+	}
+	if w := NewSyslogNetWriter("udp", "127.0.0.1:514"); w.Framing != FramingNone {
+		t.Errorf("invalid UDP framing: %d", w.Framing)
+	}
+}
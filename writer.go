@@ -2,6 +2,7 @@ package zerolog
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"path"
 	"runtime"
@@ -36,6 +37,15 @@ func (lw LevelWriterAdapter) Close() error {
 	return nil
 }
 
+// Flush calls the underlying writer's Flush method if it is a Flusher.
+// Otherwise does nothing.
+func (lw LevelWriterAdapter) Flush(ctx context.Context) error {
+	if f, ok := lw.Writer.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
 type syncWriter struct {
 	mu sync.Mutex
 	lw LevelWriter
@@ -298,6 +308,11 @@ func (w *TriggerLevelWriter) trigger() error {
 		return nil
 	}
 
+	return w.writeBuffer()
+}
+
+// writeBuffer writes the buffered log lines out. It expects lock to be held.
+func (w *TriggerLevelWriter) writeBuffer() error {
 	p := w.buf.Bytes()
 	for len(p) > 0 {
 		// We do not use bufio.Scanner here because we already have full buffer
@@ -333,6 +348,21 @@ func (w *TriggerLevelWriter) Trigger() error {
 	return w.trigger()
 }
 
+// Flush writes the buffered log lines out without changing the trigger state,
+// so they are not lost when the program crashes (see RegisterFlusher). Lines
+// are only buffered again until the next trigger.
+func (w *TriggerLevelWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.triggered || w.buf == nil {
+		return nil
+	}
+	err := w.writeBuffer()
+	w.buf.Reset()
+	return err
+}
+
 // Close closes the writer and returns the buffer to the pool.
 func (w *TriggerLevelWriter) Close() error {
 	w.mu.Lock()
@@ -5,6 +5,7 @@ package zerolog
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -281,6 +282,48 @@ func TestTriggerLevelWriter(t *testing.T) {
 	}
 }
 
+func TestTriggerLevelWriterFlush(t *testing.T) {
+	buf := bytes.Buffer{}
+	writer := TriggerLevelWriter{Writer: LevelWriterAdapter{&buf}, ConditionalLevel: DebugLevel, TriggerLevel: ErrorLevel}
+	t.Cleanup(func() { writer.Close() })
+
+	writes := []testWrite{
+		{DebugLevel, []byte("first\n")},
+		{InfoLevel, []byte("info\n")},
+		{DebugLevel, []byte("second\n")},
+	}
+	for _, w := range writes {
+		if _, err := writer.WriteLevel(w.Level, w.Line); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := writer.Flush(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if want, p := "info\nfirst\nsecond\n", buf.String(); want != p {
+		t.Errorf("Expected %q, got %q.", want, p)
+	}
+
+	// Flush does not trigger the writer: lines are buffered again and
+	// written only once.
+	buf.Reset()
+	if _, err := writer.WriteLevel(DebugLevel, []byte("third\n")); err != nil {
+		t.Error(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output, got %q.", buf.String())
+	}
+	if err := writer.Flush(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if err := writer.Flush(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if want, p := "third\n", buf.String(); want != p {
+		t.Errorf("Expected %q, got %q.", want, p)
+	}
+}
+
 func TestLevelWriterAdapter_Close(t *testing.T) {
 	// Test with closable writer
 	buf := &bytes.Buffer{}
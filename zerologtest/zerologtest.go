@@ -0,0 +1,274 @@
+// Package zerologtest provides helpers to test the events logged with
+// zerolog.
+//
+// A Recorder records the events of a logger, to assert they were logged:
+//
+//	log, rec := zerologtest.New()
+//	doSomething(log)
+//	rec.AssertLogged(t, zerolog.InfoLevel, "done", "count", 3)
+//	rec.AssertNotLogged(t, zerolog.ErrorLevel, "")
+//
+// It works with both the JSON and CBOR (binary_log build tag) encodings.
+package zerologtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/internal/cbor"
+)
+
+// UpdateGoldenEnvVar is the environment variable which, if not empty, makes
+// AssertGolden write the golden files instead of comparing them.
+const UpdateGoldenEnvVar = "ZEROLOG_UPDATE_GOLDEN"
+
+// TestingT is the subset of testing.TB used to report failures.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Event is an event recorded by a Recorder.
+type Event struct {
+	// Level is the level of the event, or zerolog.NoLevel.
+	Level zerolog.Level
+
+	// Message is the message field of the event.
+	Message string
+
+	// Fields are the other fields of the event, decoded from JSON.
+	Fields map[string]interface{}
+
+	// JSON is the event encoded in JSON, even in CBOR builds.
+	JSON []byte
+}
+
+// String returns the JSON encoding of the event.
+func (e Event) String() string {
+	return string(e.JSON)
+}
+
+// Recorder is a zerolog.LevelWriter recording the events written to it. It is
+// safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// New creates a logger writing to a new Recorder.
+func New() (zerolog.Logger, *Recorder) {
+	r := &Recorder{}
+	return zerolog.New(r), r
+}
+
+// Write implements the io.Writer interface. The level is read from the event
+// level field.
+func (r *Recorder) Write(p []byte) (n int, err error) {
+	return r.write(zerolog.NoLevel, false, p)
+}
+
+// WriteLevel implements the zerolog.LevelWriter interface.
+func (r *Recorder) WriteLevel(l zerolog.Level, p []byte) (n int, err error) {
+	return r.write(l, true, p)
+}
+
+func (r *Recorder) write(l zerolog.Level, leveled bool, p []byte) (n int, err error) {
+	js := bytes.TrimSpace(cbor.DecodeIfBinaryToBytes(p))
+	var fields map[string]interface{}
+	if err := json.Unmarshal(js, &fields); err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	e := Event{
+		Level:  l,
+		Fields: fields,
+		JSON:   append([]byte(nil), js...),
+	}
+	if s, ok := fields[zerolog.LevelFieldName].(string); ok {
+		if !leveled {
+			e.Level, _ = zerolog.ParseLevel(s)
+		}
+		delete(fields, zerolog.LevelFieldName)
+	}
+	if s, ok := fields[zerolog.MessageFieldName].(string); ok {
+		e.Message = s
+		delete(fields, zerolog.MessageFieldName)
+	}
+
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+	return len(p), nil
+}
+
+// Events returns the events recorded so far.
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// Reset removes the events recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.events = nil
+	r.mu.Unlock()
+}
+
+// Find returns the events logged at level with message msg and the given
+// fields. An empty msg matches all messages. fields are key/value pairs:
+// values are compared with the fields of the events after a JSON round trip,
+// so 42 matches a field added with Int, Int64 or Float64. It panics if
+// fields are not key/value pairs.
+func (r *Recorder) Find(level zerolog.Level, msg string, fields ...interface{}) []Event {
+	want, err := keyValues(fields)
+	if err != nil {
+		panic(err)
+	}
+	var found []Event
+	for _, e := range r.Events() {
+		if e.match(level, msg, want) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// AssertLogged checks that an event was logged at level with message msg and
+// the given fields, as defined by Find.
+func (r *Recorder) AssertLogged(t TestingT, level zerolog.Level, msg string, fields ...interface{}) bool {
+	t.Helper()
+	if len(r.Find(level, msg, fields...)) > 0 {
+		return true
+	}
+	t.Errorf("no %s event %q with fields %v logged, events:\n%s", level, msg, fields, r.dump())
+	return false
+}
+
+// AssertNotLogged checks that no event was logged at level with message msg
+// and the given fields, as defined by Find.
+func (r *Recorder) AssertNotLogged(t TestingT, level zerolog.Level, msg string, fields ...interface{}) bool {
+	t.Helper()
+	found := r.Find(level, msg, fields...)
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("unexpected %s event %q with fields %v logged: %s", level, msg, fields, found[0])
+	return false
+}
+
+// AssertGolden compares the recorded events with the golden file at path,
+// which holds one JSON event per line. The timestamp field and the ignored
+// fields are removed from the events, and their fields are sorted, so the
+// golden file does not depend on the time or encoding.
+//
+// If the UpdateGoldenEnvVar environment variable is set, the golden file is
+// written instead:
+//
+//	ZEROLOG_UPDATE_GOLDEN=1 go test ./...
+func (r *Recorder) AssertGolden(t TestingT, path string, ignore ...string) bool {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, e := range r.Events() {
+		buf.Write(e.golden(ignore))
+		buf.WriteByte('\n')
+	}
+	got := buf.Bytes()
+
+	if os.Getenv(UpdateGoldenEnvVar) != "" {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Errorf("cannot write golden file: %v", err)
+			return false
+		}
+		return true
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("cannot read golden file: %v", err)
+		return false
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("events do not match golden file %s (set %s=1 to update it):\ngot:\n%s\nwant:\n%s", path, UpdateGoldenEnvVar, got, want)
+		return false
+	}
+	return true
+}
+
+// dump returns the recorded events, one per line.
+func (r *Recorder) dump() string {
+	var lines []string
+	for _, e := range r.Events() {
+		lines = append(lines, e.String())
+	}
+	if len(lines) == 0 {
+		return "(none)"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// match reports whether e was logged at level with message msg and the want
+// fields.
+func (e Event) match(level zerolog.Level, msg string, want map[string]interface{}) bool {
+	if e.Level != level || msg != "" && e.Message != msg {
+		return false
+	}
+	for k, v := range want {
+		got, ok := e.Fields[k]
+		if !ok || !reflect.DeepEqual(got, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// golden returns the JSON encoding of e, without the timestamp and ignored
+// fields, with sorted keys.
+func (e Event) golden(ignore []string) []byte {
+	m := make(map[string]interface{}, len(e.Fields)+2)
+	for k, v := range e.Fields {
+		m[k] = v
+	}
+	delete(m, zerolog.TimestampFieldName)
+	for _, k := range ignore {
+		delete(m, k)
+	}
+	if e.Level != zerolog.NoLevel {
+		m[zerolog.LevelFieldName] = e.Level.String()
+	}
+	if e.Message != "" {
+		m[zerolog.MessageFieldName] = e.Message
+	}
+	// encoding/json sorts the keys of maps.
+	p, _ := json.Marshal(m)
+	return p
+}
+
+// keyValues converts key/value pairs into fields, normalized with a JSON
+// round trip.
+func keyValues(kv []interface{}) (map[string]interface{}, error) {
+	if len(kv)%2 != 0 {
+		return nil, fmt.Errorf("zerologtest: odd number of key/value arguments: %v", kv)
+	}
+	fields := make(map[string]interface{}, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		k, ok := kv[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("zerologtest: key %v is not a string", kv[i])
+		}
+		p, err := json.Marshal(kv[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("zerologtest: cannot encode %s: %v", k, err)
+		}
+		var v interface{}
+		if err := json.Unmarshal(p, &v); err != nil {
+			return nil, err
+		}
+		fields[k] = v
+	}
+	return fields, nil
+}
@@ -0,0 +1,97 @@
+package zerologtest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// fakeT records the failures reported by the assertions.
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder(t *testing.T) {
+	log, rec := New()
+	log = log.With().Timestamp().Logger()
+	log.Info().Int("count", 3).Str("user", "bob").Msg("done")
+	log.Warn().Err(errors.New("boom")).Msg("failed")
+	log.Log().Msg("no level")
+
+	events := rec.Events()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if e := events[0]; e.Level != zerolog.InfoLevel || e.Message != "done" || e.Fields["count"] != float64(3) {
+		t.Errorf("invalid event: %+v", e)
+	}
+	if _, ok := events[0].Fields[zerolog.TimestampFieldName]; !ok {
+		t.Errorf("expected a timestamp field: %v", events[0].Fields)
+	}
+	if e := events[2]; e.Level != zerolog.NoLevel || e.Message != "no level" {
+		t.Errorf("invalid event: %+v", e)
+	}
+
+	rec.AssertLogged(t, zerolog.InfoLevel, "done")
+	rec.AssertLogged(t, zerolog.InfoLevel, "done", "count", 3, "user", "bob")
+	rec.AssertLogged(t, zerolog.WarnLevel, "", "error", "boom")
+	rec.AssertNotLogged(t, zerolog.ErrorLevel, "")
+	rec.AssertNotLogged(t, zerolog.InfoLevel, "done", "count", 4)
+
+	ft := &fakeT{}
+	if rec.AssertLogged(ft, zerolog.DebugLevel, "done") || len(ft.errors) != 1 || !strings.Contains(ft.errors[0], `"message":"failed"`) {
+		t.Errorf("expected AssertLogged to fail and dump the events: %v", ft.errors)
+	}
+	ft = &fakeT{}
+	if rec.AssertNotLogged(ft, zerolog.InfoLevel, "", "user", "bob") || len(ft.errors) != 1 {
+		t.Errorf("expected AssertNotLogged to fail: %v", ft.errors)
+	}
+
+	rec.Reset()
+	if len(rec.Events()) != 0 {
+		t.Error("expected no events after Reset")
+	}
+}
+
+func TestRecorderWrite(t *testing.T) {
+	rec := &Recorder{}
+	if _, err := rec.Write([]byte(`{"level":"error","message":"m"}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	rec.AssertLogged(t, zerolog.ErrorLevel, "m")
+	if _, err := rec.Write([]byte("not json")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	log, rec := New()
+	log = log.With().Timestamp().Caller().Logger()
+	log.Info().Str("user", "bob").Dict("req", zerolog.Dict().Int("status", 200)).Msg("done")
+	log.Error().Bool("retry", true).Msg("failed")
+
+	rec.AssertGolden(t, filepath.Join("testdata", "golden.json"), zerolog.CallerFieldName)
+
+	path := filepath.Join(t.TempDir(), "golden.json")
+	t.Setenv(UpdateGoldenEnvVar, "1")
+	if !rec.AssertGolden(t, path) {
+		t.Fatal("expected the golden file to be written")
+	}
+	t.Setenv(UpdateGoldenEnvVar, "")
+	os.WriteFile(path, []byte("{}\n"), 0o644)
+	ft := &fakeT{}
+	if rec.AssertGolden(ft, path) || len(ft.errors) != 1 {
+		t.Errorf("expected AssertGolden to fail: %v", ft.errors)
+	}
+}
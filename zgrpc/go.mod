@@ -0,0 +1,20 @@
+module github.com/rs/zerolog/zgrpc
+
+go 1.23
+
+require (
+	github.com/rs/zerolog v1.34.0
+	google.golang.org/grpc v1.70.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/net v0.32.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241202173237-19429a94021a // indirect
+	google.golang.org/protobuf v1.35.2 // indirect
+)
+
+replace github.com/rs/zerolog => ../
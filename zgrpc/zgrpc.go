@@ -0,0 +1,263 @@
+// Package zgrpc provides gRPC interceptors for zerolog.
+//
+// Server interceptors inject a logger into the context of each call, which
+// can be retrieved using zerolog.Ctx, and log every finished call. Client
+// interceptors log every finished call using the logger found in the call's
+// context.
+package zgrpc
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Field names used by the interceptors.
+var (
+	MethodFieldName   = "grpc_method"
+	PeerFieldName     = "peer"
+	CodeFieldName     = "grpc_code"
+	DurationFieldName = "duration"
+	SentFieldName     = "msgs_sent"
+	ReceivedFieldName = "msgs_received"
+)
+
+// CodeToLevel maps a gRPC status code to the level of the log event.
+type CodeToLevel func(code codes.Code) zerolog.Level
+
+// DefaultCodeToLevel logs successful calls and client errors at info level,
+// errors which may be transient at warn level and server errors at error
+// level.
+func DefaultCodeToLevel(code codes.Code) zerolog.Level {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return zerolog.InfoLevel
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return zerolog.WarnLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+type options struct {
+	codeToLevel CodeToLevel
+	message     string
+}
+
+// Option configures the interceptors.
+type Option func(o *options)
+
+// WithCodeToLevel sets the function used to map status codes to levels.
+// DefaultCodeToLevel is used by default.
+func WithCodeToLevel(f CodeToLevel) Option {
+	return func(o *options) {
+		o.codeToLevel = f
+	}
+}
+
+// WithMessage sets the message of the logged events. Default is "grpc call".
+func WithMessage(msg string) Option {
+	return func(o *options) {
+		o.message = msg
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		codeToLevel: DefaultCodeToLevel,
+		message:     "grpc call",
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// UnaryServerInterceptor returns an interceptor injecting a copy of l, with
+// the method and peer as fields, into the context of unary calls and logging
+// each call once finished.
+func UnaryServerInterceptor(l zerolog.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		logger := serverLogger(ctx, l, info.FullMethod)
+		ctx = logger.WithContext(ctx)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		var sent int64
+		if err == nil {
+			sent = 1
+		}
+		o.log(zerolog.Ctx(ctx), err, start, sent, 1)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor injecting a copy of l, with
+// the method and peer as fields, into the context of streaming calls and
+// logging each call once finished.
+func StreamServerInterceptor(l zerolog.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		logger := serverLogger(ss.Context(), l, info.FullMethod)
+		ws := &serverStream{
+			ServerStream: ss,
+			ctx:          logger.WithContext(ss.Context()),
+		}
+		start := time.Now()
+		err := handler(srv, ws)
+		o.log(zerolog.Ctx(ws.ctx), err, start, ws.sent.Load(), ws.received.Load())
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor logging unary calls using
+// the logger found in the call's context.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		var received int64
+		if err == nil {
+			received = 1
+		}
+		o.log(clientLogger(ctx, method, cc), err, start, 1, received)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor logging streaming calls
+// using the logger found in the call's context. A call is logged once the
+// stream returns an error, io.EOF included, or once the response of a call
+// without server streaming is received.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			o.log(clientLogger(ctx, method, cc), err, start, 0, 0)
+			return nil, err
+		}
+		return &clientStream{
+			ClientStream:  cs,
+			serverStreams: desc.ServerStreams,
+			done: func(err error, sent, received int64) {
+				o.log(clientLogger(ctx, method, cc), err, start, sent, received)
+			},
+		}, nil
+	}
+}
+
+func (o options) log(l *zerolog.Logger, err error, start time.Time, sent, received int64) {
+	code := status.Code(err)
+	e := l.WithLevel(o.codeToLevel(code))
+	if !e.Enabled() {
+		return
+	}
+	e.Str(CodeFieldName, code.String()).
+		Dur(DurationFieldName, time.Since(start)).
+		Int64(SentFieldName, sent).
+		Int64(ReceivedFieldName, received)
+	if err != nil {
+		e.Err(err)
+	}
+	e.Msg(o.message)
+}
+
+func serverLogger(ctx context.Context, l zerolog.Logger, method string) zerolog.Logger {
+	c := l.With().Str(MethodFieldName, method)
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		c = c.Str(PeerFieldName, p.Addr.String())
+	}
+	return c.Logger()
+}
+
+func clientLogger(ctx context.Context, method string, cc *grpc.ClientConn) *zerolog.Logger {
+	l := zerolog.Ctx(ctx).With().Str(MethodFieldName, method)
+	if cc != nil {
+		l = l.Str(PeerFieldName, cc.Target())
+	}
+	logger := l.Logger()
+	return &logger
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx      context.Context
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Add(1)
+	}
+	return err
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	serverStreams bool
+	once          sync.Once
+	done          func(err error, sent, received int64)
+	sent          atomic.Int64
+	received      atomic.Int64
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+	} else if err != io.EOF {
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch err {
+	case nil:
+		s.received.Add(1)
+		if !s.serverStreams {
+			// RecvMsg never returns io.EOF after the single response of
+			// a call without server streaming.
+			s.finish(nil)
+		}
+	case io.EOF:
+		s.finish(nil)
+	default:
+		s.finish(err)
+	}
+	return err
+}
+
+func (s *clientStream) finish(err error) {
+	s.once.Do(func() {
+		s.done(err, s.sent.Load(), s.received.Load())
+	})
+}
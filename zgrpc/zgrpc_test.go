@@ -0,0 +1,287 @@
+package zgrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/bridge"
+	"github.com/rs/zerolog/internal/cbor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	testpb "google.golang.org/grpc/interop/grpc_testing"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bridge.Logger implements the grpclog interfaces without depending on grpc.
+var _ grpclog.DepthLoggerV2 = (*bridge.Logger)(nil)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(cbor.DecodeIfBinaryToBytes(p))
+}
+
+// events returns the logged events, waiting up to one second for at least n
+// of them to be written.
+func (b *syncBuffer) events(t *testing.T, n int) []map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		b.mu.Lock()
+		s := b.buf.String()
+		b.mu.Unlock()
+		lines := strings.Split(strings.TrimSpace(s), "\n")
+		if s != "" && len(lines) >= n || time.Now().After(deadline) {
+			var events []map[string]interface{}
+			for _, line := range lines {
+				if line == "" {
+					continue
+				}
+				var m map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &m); err != nil {
+					t.Fatalf("invalid log output %q: %v", line, err)
+				}
+				events = append(events, m)
+			}
+			return events
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// inputServer implements the client streaming StreamingInputCall method.
+type inputServer struct {
+	testpb.UnimplementedTestServiceServer
+}
+
+func (inputServer) StreamingInputCall(stream testpb.TestService_StreamingInputCallServer) error {
+	var size int32
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&testpb.StreamingInputCallResponse{AggregatedPayloadSize: size})
+		}
+		if err != nil {
+			return err
+		}
+		size += int32(len(req.GetPayload().GetBody()))
+	}
+}
+
+func setup(t *testing.T, serverOut *syncBuffer) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	logger := zerolog.New(serverOut)
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(logger)),
+		grpc.StreamInterceptor(StreamServerInterceptor(logger)),
+	)
+	hs := health.NewServer()
+	hs.SetServingStatus("known", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	testpb.RegisterTestServiceServer(srv, inputServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(WithMessage("client call"))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func checkFields(t *testing.T, got, want map[string]interface{}) {
+	t.Helper()
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("Invalid %s field, got: %v, want: %v (event: %v)", k, got[k], v, got)
+		}
+	}
+	if _, ok := got[DurationFieldName]; !ok {
+		t.Errorf("Missing %s field", DurationFieldName)
+	}
+}
+
+func TestUnary(t *testing.T) {
+	serverOut, clientOut := &syncBuffer{}, &syncBuffer{}
+	client := healthpb.NewHealthClient(setup(t, serverOut))
+	ctx := zerolog.New(clientOut).WithContext(context.Background())
+
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "known"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound error, got: %v", err)
+	}
+
+	server := serverOut.events(t, 2)
+	if len(server) != 2 {
+		t.Fatalf("Expected 2 server events, got: %v", server)
+	}
+	checkFields(t, server[0], map[string]interface{}{
+		"level":           "info",
+		MethodFieldName:   "/grpc.health.v1.Health/Check",
+		PeerFieldName:     "bufconn",
+		CodeFieldName:     "OK",
+		SentFieldName:     float64(1),
+		ReceivedFieldName: float64(1),
+		"message":         "grpc call",
+	})
+	checkFields(t, server[1], map[string]interface{}{
+		CodeFieldName: "NotFound",
+		SentFieldName: float64(0),
+	})
+	if _, ok := server[1]["error"]; !ok {
+		t.Error("Missing error field")
+	}
+
+	client2 := clientOut.events(t, 2)
+	if len(client2) != 2 {
+		t.Fatalf("Expected 2 client events, got: %v", client2)
+	}
+	checkFields(t, client2[0], map[string]interface{}{
+		MethodFieldName:   "/grpc.health.v1.Health/Check",
+		PeerFieldName:     "passthrough:///bufnet",
+		CodeFieldName:     "OK",
+		ReceivedFieldName: float64(1),
+	})
+	checkFields(t, client2[1], map[string]interface{}{
+		CodeFieldName:     "NotFound",
+		ReceivedFieldName: float64(0),
+	})
+}
+
+func TestUnaryServerLoggerInContext(t *testing.T) {
+	out := &syncBuffer{}
+	i := UnaryServerInterceptor(zerolog.New(out), WithCodeToLevel(func(codes.Code) zerolog.Level {
+		return zerolog.Disabled
+	}))
+	info := &grpc.UnaryServerInfo{FullMethod: "/svc/Method"}
+	_, err := i(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		zerolog.Ctx(ctx).Info().Msg("from handler")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := out.events(t, 1)
+	if len(events) != 1 {
+		t.Fatalf("Expected only the handler event, got: %v", events)
+	}
+	if events[0][MethodFieldName] != "/svc/Method" || events[0]["message"] != "from handler" {
+		t.Errorf("Invalid handler event: %v", events[0])
+	}
+}
+
+func TestStream(t *testing.T) {
+	serverOut, clientOut := &syncBuffer{}, &syncBuffer{}
+	client := healthpb.NewHealthClient(setup(t, serverOut))
+	ctx, cancel := context.WithCancel(zerolog.New(clientOut).WithContext(context.Background()))
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "known"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("Expected Canceled error, got: %v", err)
+	}
+
+	clientEvents := clientOut.events(t, 1)
+	if len(clientEvents) != 1 {
+		t.Fatalf("Expected 1 client event, got: %v", clientEvents)
+	}
+	checkFields(t, clientEvents[0], map[string]interface{}{
+		MethodFieldName:   "/grpc.health.v1.Health/Watch",
+		CodeFieldName:     "Canceled",
+		SentFieldName:     float64(1),
+		ReceivedFieldName: float64(1),
+		"message":         "client call",
+	})
+
+	serverEvents := serverOut.events(t, 1)
+	if len(serverEvents) != 1 {
+		t.Fatalf("Expected 1 server event, got: %v", serverEvents)
+	}
+	checkFields(t, serverEvents[0], map[string]interface{}{
+		MethodFieldName:   "/grpc.health.v1.Health/Watch",
+		SentFieldName:     float64(1),
+		ReceivedFieldName: float64(1),
+	})
+}
+
+func TestClientStream(t *testing.T) {
+	serverOut, clientOut := &syncBuffer{}, &syncBuffer{}
+	client := testpb.NewTestServiceClient(setup(t, serverOut))
+	ctx := zerolog.New(clientOut).WithContext(context.Background())
+
+	stream, err := client.StreamingInputCall(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"foo", "bar"} {
+		if err := stream.Send(&testpb.StreamingInputCallRequest{Payload: &testpb.Payload{Body: []byte(body)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetAggregatedPayloadSize() != 6 {
+		t.Errorf("Invalid aggregated payload size: %d", resp.GetAggregatedPayloadSize())
+	}
+
+	clientEvents := clientOut.events(t, 1)
+	if len(clientEvents) != 1 {
+		t.Fatalf("Expected 1 client event, got: %v", clientEvents)
+	}
+	checkFields(t, clientEvents[0], map[string]interface{}{
+		MethodFieldName:   "/grpc.testing.TestService/StreamingInputCall",
+		CodeFieldName:     "OK",
+		SentFieldName:     float64(2),
+		ReceivedFieldName: float64(1),
+		"message":         "client call",
+	})
+
+	serverEvents := serverOut.events(t, 1)
+	if len(serverEvents) != 1 {
+		t.Fatalf("Expected 1 server event, got: %v", serverEvents)
+	}
+	checkFields(t, serverEvents[0], map[string]interface{}{
+		MethodFieldName:   "/grpc.testing.TestService/StreamingInputCall",
+		CodeFieldName:     "OK",
+		SentFieldName:     float64(1),
+		ReceivedFieldName: float64(2),
+	})
+}